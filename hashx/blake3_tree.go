@@ -0,0 +1,89 @@
+package hashx
+
+import (
+	"encoding/binary"
+	"math/bits"
+
+	"lukechampine.com/blake3/guts"
+)
+
+// b3Tree hashes subtrees of a BLAKE3 input independently and joins them,
+// producing the same digest as hashing the input in one pass.
+//
+// github.com/zeebo/blake3, used everywhere else, only hashes whole inputs. The
+// guts package of lukechampine.com/blake3 exposes the compression function,
+// chunk counters and chaining values that subtree hashing needs.
+type b3Tree struct {
+	key   [8]uint32
+	flags uint32
+}
+
+func newB3Tree(key []byte) b3Tree {
+	if key == nil {
+		return b3Tree{key: guts.IV}
+	}
+	t := b3Tree{flags: guts.FlagKeyedHash}
+	for i := range t.key {
+		t.key[i] = binary.LittleEndian.Uint32(key[4*i:])
+	}
+	return t
+}
+
+// subtree returns the node covering data, whose first chunk has the given counter.
+// Subtrees split at the largest power of two number of chunks that leaves at least
+// one chunk on the right, as the BLAKE3 spec requires.
+func (t b3Tree) subtree(data []byte, counter uint64) guts.Node {
+	const simd = guts.MaxSIMD * guts.ChunkSize
+
+	if len(data) == simd {
+		return guts.CompressBuffer((*[simd]byte)(data), simd, &t.key, counter, t.flags)
+	}
+	if len(data) < simd {
+		var buf [simd]byte
+		copy(buf[:], data)
+		return guts.CompressBuffer(&buf, len(data), &t.key, counter, t.flags)
+	}
+
+	chunks := uint64(len(data)+guts.ChunkSize-1) / guts.ChunkSize
+	left := largestPowerOfTwoBelow(chunks)
+	split := left * guts.ChunkSize
+	return guts.ParentNode(
+		guts.ChainingValue(t.subtree(data[:split], counter)),
+		guts.ChainingValue(t.subtree(data[split:], counter+left)),
+		&t.key, t.flags,
+	)
+}
+
+// join combines the chaining values of consecutive units of unitChunks chunks
+// each into the node covering chunks lo to hi.
+func (t b3Tree) join(cvs [][8]uint32, unitChunks, lo, hi uint64) guts.Node {
+	left := largestPowerOfTwoBelow(hi - lo)
+	return guts.ParentNode(
+		t.joinCV(cvs, unitChunks, lo, lo+left),
+		t.joinCV(cvs, unitChunks, lo+left, hi),
+		&t.key, t.flags,
+	)
+}
+
+func (t b3Tree) joinCV(cvs [][8]uint32, unitChunks, lo, hi uint64) [8]uint32 {
+	if hi-lo <= unitChunks {
+		return cvs[lo/unitChunks]
+	}
+	return guts.ChainingValue(t.join(cvs, unitChunks, lo, hi))
+}
+
+// rootBytes reads size bytes of output from the root node n.
+func (t b3Tree) rootBytes(n guts.Node, size int) []byte {
+	n.Flags |= guts.FlagRoot
+	out := make([]byte, 0, size+guts.BlockSize)
+	for n.Counter = 0; len(out) < size; n.Counter++ {
+		block := guts.WordsToBytes(guts.CompressNode(n))
+		out = append(out, block[:]...)
+	}
+	return out[:size]
+}
+
+// largestPowerOfTwoBelow returns the largest power of two strictly less than n, for n > 1.
+func largestPowerOfTwoBelow(n uint64) uint64 {
+	return 1 << (63 - bits.LeadingZeros64(n-1))
+}
@@ -0,0 +1,117 @@
+package hashx
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/bits"
+
+	"github.com/atlastore/belt/hashx/internal/mix"
+)
+
+var ErrInvalidBuckets = errors.New("hashx: number of buckets must be positive")
+
+// JumpHash maps key to one of buckets using the jump consistent hash of Lamping and Veach.
+// Growing buckets from n to n+1 moves only 1/(n+1) of the keys, all of them into the new bucket.
+func JumpHash(key uint64, buckets int) (int, error) {
+	if buckets <= 0 {
+		return 0, ErrInvalidBuckets
+	}
+
+	var b, j int64 = -1, 0
+	for j < int64(buckets) {
+		b = j
+		key = key*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1)))
+	}
+	return int(b), nil
+}
+
+// Bucket maps x uniformly to [0, buckets) using Lemire's multiply-shift range reduction.
+// It relies on the high bits of x, so x should be a well mixed hash.
+// Unlike x % buckets it has no modulo bias: the rare values that would be biased are
+// rejected and x is deterministically re-mixed until one is accepted.
+func Bucket(x uint64, buckets int) (int, error) {
+	if buckets <= 0 {
+		return 0, ErrInvalidBuckets
+	}
+	return int(reduce(x, uint64(buckets), func(prev uint64) uint64 {
+		return mix.Mix64(prev + 0x9e3779b97f4a7c15)
+	})), nil
+}
+
+func reduce(x, n uint64, next func(uint64) uint64) uint64 {
+	hi, lo := bits.Mul64(x, n)
+	if lo < n {
+		threshold := -n % n
+		for lo < threshold {
+			x = next(x)
+			hi, lo = bits.Mul64(x, n)
+		}
+	}
+	return hi
+}
+
+// Uint64 returns the first 8 bytes of the digest as a big endian integer,
+// left padding shorter digests, the same way QuickMod reads them.
+func (h HashSum) Uint64() uint64 {
+	return digestWord(h.data, 0)
+}
+
+// Jump maps the digest to a bucket with JumpHash.
+func (h HashSum) Jump(buckets int) (int, error) {
+	return JumpHash(h.Uint64(), buckets)
+}
+
+// Bucket maps the digest uniformly to [0, buckets). Rejected values are replaced
+// by the following 8 byte words of the digest before falling back to re-mixing.
+// Digests shorter than 8 bytes, such as CRC32 sums, are passed through the
+// SplitMix64 finalizer first, since the range reduction relies on the high bits.
+func (h HashSum) Bucket(buckets int) (int, error) {
+	if buckets <= 0 {
+		return 0, ErrInvalidBuckets
+	}
+
+	x := h.Uint64()
+	if len(h.data) < 8 {
+		x = mix.Mix64(x)
+	}
+	word := 0
+	return int(reduce(x, uint64(buckets), func(prev uint64) uint64 {
+		word++
+		if (word+1)*8 <= len(h.data) {
+			return digestWord(h.data, word)
+		}
+		return mix.Mix64(prev + 0x9e3779b97f4a7c15)
+	})), nil
+}
+
+// Jump hashes data and maps it to a bucket with JumpHash.
+// The hash is passed through the SplitMix64 finalizer first, as for Ring.
+func (algo64 Hash64Algorithm) Jump(data []byte, buckets int) (int, error) {
+	h, err := algo64.Sum64(data)
+	if err != nil {
+		return 0, err
+	}
+	return JumpHash(mix.Mix64(h), buckets)
+}
+
+// Bucket hashes data and maps it uniformly to [0, buckets).
+// The hash is passed through the SplitMix64 finalizer first, as for Ring.
+func (algo64 Hash64Algorithm) Bucket(data []byte, buckets int) (int, error) {
+	h, err := algo64.Sum64(data)
+	if err != nil {
+		return 0, err
+	}
+	return Bucket(mix.Mix64(h), buckets)
+}
+
+func digestWord(data []byte, word int) uint64 {
+	buf := make([]byte, 8)
+	data = data[min(word*8, len(data)):]
+	if len(data) > 8 {
+		copy(buf, data[:8])
+	} else {
+		copy(buf[8-len(data):], data)
+	}
+	return binary.BigEndian.Uint64(buf)
+}
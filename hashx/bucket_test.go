@@ -0,0 +1,167 @@
+package hashx
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/atlastore/belt/hashx/internal/mix"
+)
+
+// chiSquare returns the chi-square statistic of counts against a uniform expectation.
+func chiSquare(counts []int, total int) float64 {
+	want := float64(total) / float64(len(counts))
+	var x float64
+	for _, c := range counts {
+		d := float64(c) - want
+		x += d * d / want
+	}
+	return x
+}
+
+func TestJumpHash(t *testing.T) {
+	// A single bucket owns every key and bucket 0 always survives growth.
+	for _, c := range []struct {
+		key     uint64
+		buckets int
+		want    int
+	}{
+		{key: 0, buckets: 1, want: 0},
+		{key: 1, buckets: 1, want: 0},
+		{key: 0xdeadbeef, buckets: 1, want: 0},
+		{key: 1, buckets: 2, want: 0},
+	} {
+		got, err := JumpHash(c.key, c.buckets)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != c.want {
+			t.Fatalf("JumpHash(%d, %d) = %d, expected %d", c.key, c.buckets, got, c.want)
+		}
+	}
+
+	if _, err := JumpHash(1, 0); err != ErrInvalidBuckets {
+		t.Fatalf("expected ErrInvalidBuckets, got %v", err)
+	}
+}
+
+func TestJumpHashConsistency(t *testing.T) {
+	const keys = 20000
+
+	moved := 0
+	for i := 0; i < keys; i++ {
+		k := FNV64.HashString(fmt.Sprintf("object-%d", i))
+		before, _ := JumpHash(k, 10)
+		after, _ := JumpHash(k, 11)
+		if before != after {
+			if after != 10 {
+				t.Fatalf("key moved from %d to %d instead of the new bucket", before, after)
+			}
+			moved++
+		}
+	}
+
+	// About 1/11 of the keys should move.
+	if frac := float64(moved) / keys; math.Abs(frac-1.0/11) > 0.02 {
+		t.Fatalf("%.3f of keys moved, expected about %.3f", frac, 1.0/11)
+	}
+}
+
+func TestBucketDistribution(t *testing.T) {
+	const (
+		keys    = 60000
+		buckets = 12
+		// Critical chi-square value for 11 degrees of freedom at p = 0.001.
+		critical = 31.26
+	)
+
+	cases := map[string]func(i int) (int, error){
+		"jump": func(i int) (int, error) {
+			return FNV64.Jump([]byte(fmt.Sprintf("object-%d", i)), buckets)
+		},
+		"lemire": func(i int) (int, error) {
+			return CRC64.Bucket([]byte(fmt.Sprintf("object-%d", i)), buckets)
+		},
+		"hashsum": func(i int) (int, error) {
+			sum, err := HashString(SHA256, fmt.Sprintf("object-%d", i))
+			if err != nil {
+				return 0, err
+			}
+			return sum.Bucket(buckets)
+		},
+	}
+
+	for name, fn := range cases {
+		t.Run(name, func(t *testing.T) {
+			counts := make([]int, buckets)
+			for i := 0; i < keys; i++ {
+				b, err := fn(i)
+				if err != nil {
+					t.Fatal(err)
+				}
+				counts[b]++
+			}
+			if x := chiSquare(counts, keys); x > critical {
+				t.Fatalf("chi-square %.2f exceeds %.2f: %v", x, critical, counts)
+			}
+		})
+	}
+}
+
+func TestBucketUnbiased(t *testing.T) {
+	// With n = 3 * 2^61, plain modulo hits the lower two thirds of the range with
+	// probability 3/8 each and the top third with 2/8. Lemire's reduction keeps each
+	// third equally likely.
+	n := 3 << 61
+	counts := make([]int, 3)
+	for i := uint64(0); i < 30000; i++ {
+		b, err := Bucket(mix.Mix64(i), n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[b/(1<<61)]++
+	}
+	if x := chiSquare(counts, 30000); x > 13.8 {
+		t.Fatalf("biased buckets: %v", counts)
+	}
+
+	if _, err := Bucket(1, -1); err != ErrInvalidBuckets {
+		t.Fatalf("expected ErrInvalidBuckets, got %v", err)
+	}
+}
+
+func TestModNonPositive(t *testing.T) {
+	digest := []byte{0xde, 0xad, 0xbe, 0xef, 0x01, 0x02, 0x03, 0x04, 0x05}
+	for _, mod := range []int64{0, -1, math.MinInt64} {
+		if got := QuickMod(digest, mod); got != 0 {
+			t.Fatalf("QuickMod(%d) = %d, expected 0", mod, got)
+		}
+		if got := Mod(digest, mod); got != 0 {
+			t.Fatalf("Mod(%d) = %d, expected 0", mod, got)
+		}
+	}
+	if got := QuickMod(digest, 7); got != int64(0xdeadbeef01020304%7) {
+		t.Fatalf("QuickMod(7) = %d", got)
+	}
+}
+
+// Short digests are left padded by Uint64, so without mixing every one of them
+// would land in bucket 0.
+func TestBucketShortDigest(t *testing.T) {
+	const buckets = 8
+	counts := make([]int, buckets)
+	for i := 0; i < 8000; i++ {
+		sum, err := HashString(CRC32, fmt.Sprintf("key-%d", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := sum.Bucket(buckets)
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[b]++
+	}
+	if x := chiSquare(counts, 8000); x > 24.3 {
+		t.Fatalf("CRC32 sums are not spread over the buckets: %v", counts)
+	}
+}
@@ -0,0 +1,199 @@
+// Package chunker splits streams into content-defined chunks, so that regions
+// shared between two versions of an object produce identical chunks even when
+// data was inserted or removed before them.
+//
+// Cut points are chosen by a rolling hash over the last few bytes of input,
+// either Gear as used by FastCDC or a Rabin fingerprint, and every chunk carries
+// a strong digest from any hashx algorithm for deduplication.
+package chunker
+
+import (
+	"errors"
+	"hash"
+	"io"
+	"math/bits"
+
+	"github.com/atlastore/belt/hashx"
+)
+
+// Method selects the rolling hash used to find cut points.
+type Method int
+
+const (
+	// FastCDC uses the Gear rolling hash with normalized chunking. It is the fastest method.
+	FastCDC Method = iota
+	// Rabin uses a Rabin fingerprint over a 64 byte window, as in LBFS and restic.
+	Rabin
+)
+
+const (
+	DefaultMinSize = 2 * 1024
+	DefaultAvgSize = 8 * 1024
+	DefaultMaxSize = 64 * 1024
+)
+
+var ErrInvalidOptions = errors.New("chunker: sizes must satisfy 64 <= min <= avg <= max")
+
+// Options configures a Chunker. Zero sizes take their defaults.
+// AvgSize is rounded to the nearest power of two.
+type Options struct {
+	Method  Method
+	MinSize int
+	AvgSize int
+	MaxSize int
+}
+
+// Chunk is one content-defined piece of the input.
+type Chunk struct {
+	Offset int64
+	Length int
+	Sum    *hashx.HashSum
+	// Data holds the chunk's bytes. It is only valid until the next call to Next.
+	Data []byte
+}
+
+// Chunker reads a stream and returns its chunks in order.
+type Chunker struct {
+	r    io.Reader
+	algo string
+	h    hash.Hash
+	cut  func(data []byte) int
+
+	buf    []byte
+	start  int
+	end    int
+	offset int64
+	eof    bool
+}
+
+// New creates a Chunker that reads from r and hashes every chunk with algo.
+func New[T hashx.AnyHashAlgorithm](r io.Reader, algo T, opts Options, key ...[]byte) (*Chunker, error) {
+	opts = opts.withDefaults()
+	if opts.MinSize < 64 || opts.MinSize > opts.AvgSize || opts.AvgSize > opts.MaxSize {
+		return nil, ErrInvalidOptions
+	}
+
+	fn, err := hashx.GetHash(algo, key...)
+	if err != nil {
+		return nil, err
+	}
+
+	var cut func([]byte) int
+	switch opts.Method {
+	case FastCDC:
+		cut = newGear(opts).cut
+	case Rabin:
+		cut = newRabin(opts).cut
+	default:
+		return nil, ErrInvalidOptions
+	}
+
+	return &Chunker{
+		r:    r,
+		algo: string(algo),
+		h:    fn(),
+		cut:  cut,
+		buf:  make([]byte, 2*opts.MaxSize),
+	}, nil
+}
+
+// Split reads r to the end and returns all of its chunks. Each chunk's Data is a
+// copy, so it stays valid.
+func Split[T hashx.AnyHashAlgorithm](r io.Reader, algo T, opts Options, key ...[]byte) ([]Chunk, error) {
+	c, err := New(r, algo, opts, key...)
+	if err != nil {
+		return nil, err
+	}
+
+	var chunks []Chunk
+	for {
+		chunk, err := c.Next()
+		if err == io.EOF {
+			return chunks, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		chunk.Data = append([]byte(nil), chunk.Data...)
+		chunks = append(chunks, *chunk)
+	}
+}
+
+// Next returns the next chunk, or io.EOF once the input is exhausted.
+func (c *Chunker) Next() (*Chunk, error) {
+	if err := c.fill(); err != nil {
+		return nil, err
+	}
+	if c.start == c.end {
+		return nil, io.EOF
+	}
+
+	data := c.buf[c.start:c.end]
+	n := c.cut(data)
+	data = data[:n]
+
+	c.h.Reset()
+	c.h.Write(data)
+	sum, err := hashx.NewHashSum(c.algo, c.h.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+
+	chunk := &Chunk{
+		Offset: c.offset,
+		Length: n,
+		Sum:    sum,
+		Data:   data,
+	}
+	c.start += n
+	c.offset += int64(n)
+	return chunk, nil
+}
+
+// fill makes sure at least MaxSize bytes are buffered unless the input ended.
+func (c *Chunker) fill() error {
+	maxSize := len(c.buf) / 2
+	if c.eof || c.end-c.start >= maxSize {
+		return nil
+	}
+
+	c.end = copy(c.buf, c.buf[c.start:c.end])
+	c.start = 0
+	n, err := io.ReadFull(c.r, c.buf[c.end:])
+	c.end += n
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		c.eof = true
+		return nil
+	}
+	return err
+}
+
+func (o Options) withDefaults() Options {
+	if o.MinSize == 0 {
+		o.MinSize = DefaultMinSize
+	}
+	if o.AvgSize == 0 {
+		o.AvgSize = DefaultAvgSize
+	}
+	if o.MaxSize == 0 {
+		o.MaxSize = DefaultMaxSize
+	}
+	return o
+}
+
+// maskBits returns log2 of the average size, rounded to the nearest integer.
+func (o Options) maskBits() int {
+	b := bits.Len(uint(o.AvgSize)) - 1
+	if o.AvgSize-(1<<b) > (1<<(b+1))-o.AvgSize {
+		b++
+	}
+	return b
+}
+
+// boundaries clamps a buffer to the maximum size and returns the point where the
+// stricter mask gives way to the looser one.
+func (o Options) boundaries(n int) (limit, normal int) {
+	limit = min(n, o.MaxSize)
+	normal = min(limit, 1<<o.maskBits())
+	return limit, normal
+}
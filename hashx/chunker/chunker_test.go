@@ -0,0 +1,166 @@
+package chunker
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/atlastore/belt/hashx"
+)
+
+func randomData(seed int64, n int) []byte {
+	data := make([]byte, n)
+	rand.New(rand.NewSource(seed)).Read(data)
+	return data
+}
+
+var methods = map[string]Method{"fastcdc": FastCDC, "rabin": Rabin}
+
+func TestSplit(t *testing.T) {
+	data := randomData(1, 4<<20)
+	opts := Options{MinSize: 2048, AvgSize: 8192, MaxSize: 32768}
+
+	for name, method := range methods {
+		t.Run(name, func(t *testing.T) {
+			opts.Method = method
+			chunks, err := Split(bytes.NewReader(data), hashx.SHA256, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var offset int64
+			var joined []byte
+			for i, c := range chunks {
+				if c.Offset != offset || c.Length != len(c.Data) {
+					t.Fatalf("chunk %d at %d with length %d, expected offset %d", i, c.Offset, c.Length, offset)
+				}
+				if c.Length > opts.MaxSize || (c.Length < opts.MinSize && i != len(chunks)-1) {
+					t.Fatalf("chunk %d has length %d outside [%d, %d]", i, c.Length, opts.MinSize, opts.MaxSize)
+				}
+				if err := c.Sum.VerifyBytes(c.Data); err != nil {
+					t.Fatalf("chunk %d: %v", i, err)
+				}
+				offset += int64(c.Length)
+				joined = append(joined, c.Data...)
+			}
+			if !bytes.Equal(joined, data) {
+				t.Fatal("chunks do not reassemble the input")
+			}
+
+			avg := len(data) / len(chunks)
+			if avg < opts.AvgSize/2 || avg > opts.AvgSize*2 {
+				t.Fatalf("average chunk size %d is far from %d", avg, opts.AvgSize)
+			}
+		})
+	}
+}
+
+func TestShiftResistance(t *testing.T) {
+	data := randomData(2, 2<<20)
+	edited := append(append([]byte("a few inserted bytes"), data[:1<<20]...), data[1<<20+100:]...)
+
+	for name, method := range methods {
+		t.Run(name, func(t *testing.T) {
+			before, _ := Split(bytes.NewReader(data), hashx.SHA256, Options{Method: method})
+			after, _ := Split(bytes.NewReader(edited), hashx.SHA256, Options{Method: method})
+
+			seen := make(map[string]bool)
+			for _, c := range before {
+				seen[c.Sum.Encode()] = true
+			}
+			shared := 0
+			for _, c := range after {
+				if seen[c.Sum.Encode()] {
+					shared += c.Length
+				}
+			}
+
+			// Only the chunks around the two edits should change.
+			if shared < len(data)*9/10 {
+				t.Fatalf("only %d of %d bytes were shared after the edits", shared, len(data))
+			}
+		})
+	}
+}
+
+func TestChunkerNext(t *testing.T) {
+	key := []byte("dedupe key")
+	c, err := New(bytes.NewReader(randomData(3, 100000)), hashx.Blake2b, Options{}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		chunk, err := c.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := chunk.Sum.VerifyBytes(chunk.Data, key); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	empty, _ := Split(bytes.NewReader(nil), hashx.SHA256, Options{})
+	if len(empty) != 0 {
+		t.Fatalf("expected no chunks for empty input, got %d", len(empty))
+	}
+
+	for _, opts := range []Options{
+		{MinSize: 16},
+		{MinSize: 8192, AvgSize: 4096},
+		{AvgSize: 1 << 20},
+		{Method: Method(7)},
+	} {
+		if _, err := New(bytes.NewReader(nil), hashx.SHA256, opts); err != ErrInvalidOptions {
+			t.Fatalf("%+v: expected ErrInvalidOptions, got %v", opts, err)
+		}
+	}
+	if _, err := New(bytes.NewReader(nil), hashx.Blake2b, Options{}); err != hashx.ErrKeyRequired {
+		t.Fatalf("expected ErrKeyRequired, got %v", err)
+	}
+}
+
+func TestRabinRolling(t *testing.T) {
+	data := randomData(4, 1000)
+
+	var window [rabinWindow]byte
+	var wpos int
+	var digest uint64
+	for i, b := range data {
+		digest ^= rabinOut[window[wpos]]
+		window[wpos] = b
+		wpos = (wpos + 1) % rabinWindow
+		digest = (digest<<8 | uint64(b)) ^ rabinMod[digest>>(rabinDegree-8)]
+
+		if i < rabinWindow {
+			continue
+		}
+		var want uint64
+		for _, w := range data[i-rabinWindow+1 : i+1] {
+			want = rabinAppend(want, w)
+		}
+		if digest != want {
+			t.Fatalf("rolling fingerprint at %d is %x, expected %x", i, digest, want)
+		}
+	}
+}
+
+func BenchmarkChunker(b *testing.B) {
+	data := randomData(5, 64<<20)
+	for name, method := range methods {
+		b.Run(name, func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				c, _ := New(bytes.NewReader(data), hashx.SHA256, Options{Method: method})
+				for {
+					if _, err := c.Next(); err != nil {
+						break
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package chunker
+
+// gearTable maps every byte to a fixed pseudo-random value. It is derived from a
+// SplitMix64 sequence so that cut points, and therefore chunk digests, never change.
+var gearTable = func() (t [256]uint64) {
+	x := uint64(0x6a09e667f3bcc908)
+	for i := range t {
+		x += 0x9e3779b97f4a7c15
+		z := x
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		t[i] = z ^ (z >> 31)
+	}
+	return t
+}()
+
+// gear implements FastCDC (Xia et al.). The hash is shifted left once per byte,
+// so its high bits depend only on the last 64 bytes. Normalized chunking uses a
+// mask with two extra bits before the average size and two fewer after it, which
+// narrows the spread of chunk sizes around the average.
+type gear struct {
+	opts  Options
+	maskS uint64
+	maskL uint64
+}
+
+func newGear(opts Options) *gear {
+	b := opts.maskBits()
+	return &gear{
+		opts:  opts,
+		maskS: highBits(b + 2),
+		maskL: highBits(max(b-2, 1)),
+	}
+}
+
+func highBits(n int) uint64 {
+	return ^uint64(0) << (64 - n)
+}
+
+func (g *gear) cut(data []byte) int {
+	limit, normal := g.opts.boundaries(len(data))
+	if limit <= g.opts.MinSize {
+		return limit
+	}
+
+	var h uint64
+	i := g.opts.MinSize
+	for ; i < normal; i++ {
+		h = h<<1 + gearTable[data[i]]
+		if h&g.maskS == 0 {
+			return i + 1
+		}
+	}
+	for ; i < limit; i++ {
+		h = h<<1 + gearTable[data[i]]
+		if h&g.maskL == 0 {
+			return i + 1
+		}
+	}
+	return limit
+}
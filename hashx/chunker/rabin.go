@@ -0,0 +1,99 @@
+package chunker
+
+import "math/bits"
+
+const (
+	rabinWindow = 64
+	// rabinPolynomial is an irreducible polynomial of degree 53 over GF(2).
+	rabinPolynomial uint64 = 0x3da3358b4dc173
+)
+
+var rabinDegree = polDeg(rabinPolynomial)
+
+// rabinTables hold, for every byte, the value to cancel when it leaves the window
+// and the reduction to apply when it is shifted out of the top of the fingerprint.
+var rabinOut, rabinMod = func() (out, mod [256]uint64) {
+	for b := range 256 {
+		var h uint64
+		h = rabinAppend(h, byte(b))
+		for range rabinWindow - 1 {
+			h = rabinAppend(h, 0)
+		}
+		out[b] = h
+		mod[b] = polMod(uint64(b)<<rabinDegree, rabinPolynomial) | uint64(b)<<rabinDegree
+	}
+	return out, mod
+}()
+
+// rabin finds cut points with a Rabin fingerprint of the last rabinWindow bytes,
+// with the same normalized masks as gear applied to the low bits.
+type rabin struct {
+	opts  Options
+	maskS uint64
+	maskL uint64
+}
+
+func newRabin(opts Options) *rabin {
+	b := opts.maskBits()
+	return &rabin{
+		opts:  opts,
+		maskS: 1<<(b+2) - 1,
+		maskL: 1<<max(b-2, 1) - 1,
+	}
+}
+
+func (r *rabin) cut(data []byte) int {
+	limit, normal := r.opts.boundaries(len(data))
+	if limit <= r.opts.MinSize {
+		return limit
+	}
+
+	// Start one window before the minimum size so the fingerprint at the first
+	// candidate cut point covers a full window, whatever preceded it.
+	var window [rabinWindow]byte
+	var wpos int
+	var digest uint64
+	shift := rabinDegree - 8
+	slide := func(b byte) {
+		digest ^= rabinOut[window[wpos]]
+		window[wpos] = b
+		wpos = (wpos + 1) % rabinWindow
+		index := digest >> shift
+		digest = (digest<<8 | uint64(b)) ^ rabinMod[index]
+	}
+
+	i := max(r.opts.MinSize-rabinWindow, 0)
+	for ; i < r.opts.MinSize; i++ {
+		slide(data[i])
+	}
+	for ; i < normal; i++ {
+		slide(data[i])
+		if digest&r.maskS == 0 {
+			return i + 1
+		}
+	}
+	for ; i < limit; i++ {
+		slide(data[i])
+		if digest&r.maskL == 0 {
+			return i + 1
+		}
+	}
+	return limit
+}
+
+func rabinAppend(h uint64, b byte) uint64 {
+	return polMod(h<<8|uint64(b), rabinPolynomial)
+}
+
+func polDeg(p uint64) int {
+	return bits.Len64(p) - 1
+}
+
+// polMod reduces x modulo p, both polynomials over GF(2).
+func polMod(x, p uint64) uint64 {
+	dp := polDeg(p)
+	for d := polDeg(x); d >= dp; d = polDeg(x) {
+		x ^= p << (d - dp)
+	}
+	return x
+}
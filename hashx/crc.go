@@ -0,0 +1,125 @@
+package hashx
+
+import (
+	"errors"
+	"hash"
+	"hash/crc32"
+	"hash/crc64"
+)
+
+var ErrNegativeLength = errors.New("hashx: length must not be negative")
+
+// Reflected generator polynomial of CRC-64/NVME.
+const crc64NVMEPoly = 0x9a6c9329ac4bc9b5
+
+// CRC tables are built once; crc32 picks the SSE4.2 or ARMv8 instructions for the
+// Castagnoli table on its own.
+var (
+	crc32CTable    = crc32.MakeTable(crc32.Castagnoli)
+	crc64ISOTable  = crc64.MakeTable(crc64.ISO)
+	crc64ECMATable = crc64.MakeTable(crc64.ECMA)
+	crc64NVMETable = crc64.MakeTable(crc64NVMEPoly)
+)
+
+// crcPolys holds the reflected polynomial of every CRC algorithm, for Combine.
+var (
+	crc32Polys = map[Hash32Algorithm]uint64{
+		CRC32:  crc32.IEEE,
+		CRC32C: crc32.Castagnoli,
+	}
+	crc64Polys = map[Hash64Algorithm]uint64{
+		CRC64:      crc64.ISO,
+		CRC64_ECMA: crc64.ECMA,
+		CRC64_NVME: crc64NVMEPoly,
+	}
+)
+
+func init() {
+	Register32(CRC32C, func() hash.Hash32 {
+		return crc32.New(crc32CTable)
+	})
+	setSum32(CRC32C, func(p []byte) uint32 {
+		return crc32.Checksum(p, crc32CTable)
+	})
+	Register64(CRC64_ECMA, func() hash.Hash64 {
+		return crc64.New(crc64ECMATable)
+	})
+	setSum64(CRC64_ECMA, func(p []byte) uint64 {
+		return crc64.Checksum(p, crc64ECMATable)
+	})
+	Register64(CRC64_NVME, func() hash.Hash64 {
+		return crc64.New(crc64NVMETable)
+	})
+	setSum64(CRC64_NVME, func(p []byte) uint64 {
+		return crc64.Checksum(p, crc64NVMETable)
+	})
+}
+
+// Combine returns the CRC of the concatenation of two parts from the CRC of each
+// part and the length of the second, without reading the data again.
+// It returns ErrUnsupported for algorithms that are not CRCs and ErrNegativeLength
+// if len2 is negative.
+func (algo32 Hash32Algorithm) Combine(crc1, crc2 uint32, len2 int64) (uint32, error) {
+	poly, ok := crc32Polys[normalizeAlgName[Hash32Algorithm](string(algo32))]
+	if !ok {
+		return 0, ErrUnsupported
+	}
+	if len2 < 0 {
+		return 0, ErrNegativeLength
+	}
+	return uint32(crcCombine(poly, 32, uint64(crc1), uint64(crc2), len2)), nil
+}
+
+// Combine returns the CRC of the concatenation of two parts from the CRC of each
+// part and the length of the second, without reading the data again.
+// It returns ErrUnsupported for algorithms that are not CRCs and ErrNegativeLength
+// if len2 is negative.
+func (algo64 Hash64Algorithm) Combine(crc1, crc2 uint64, len2 int64) (uint64, error) {
+	poly, ok := crc64Polys[normalizeAlgName[Hash64Algorithm](string(algo64))]
+	if !ok {
+		return 0, ErrUnsupported
+	}
+	if len2 < 0 {
+		return 0, ErrNegativeLength
+	}
+	return crcCombine(poly, 64, crc1, crc2, len2), nil
+}
+
+// crcCombine implements the zlib crc32_combine method for any reflected CRC of the
+// given width: appending len2 bytes multiplies the first CRC by x^(8*len2) modulo
+// the polynomial, and the pre and post conditioning of both parts cancel out.
+// Polynomials are stored reflected, so x^0 is the top bit. len2 must not be negative.
+func crcCombine(poly uint64, width uint, crc1, crc2 uint64, len2 int64) uint64 {
+	if len2 < 0 {
+		panic("hashx: negative length in crcCombine")
+	}
+	if len2 == 0 {
+		return crc1 ^ crc2
+	}
+
+	one := uint64(1) << (width - 1)
+	mul := func(a, b uint64) uint64 {
+		var p uint64
+		for m := one; m != 0; m >>= 1 {
+			if a&m != 0 {
+				p ^= b
+			}
+			if b&1 != 0 {
+				b = b>>1 ^ poly
+			} else {
+				b >>= 1
+			}
+		}
+		return p
+	}
+
+	// Square and multiply x^8 by the bits of len2.
+	power, shift := one>>8, one
+	for n := uint64(len2); n != 0; n >>= 1 {
+		if n&1 != 0 {
+			shift = mul(power, shift)
+		}
+		power = mul(power, power)
+	}
+	return mul(shift, crc1) ^ crc2
+}
@@ -0,0 +1,62 @@
+package hashx
+
+import (
+	"crypto/rand"
+	"testing"
+)
+
+func TestCRCCombine(t *testing.T) {
+	data := make([]byte, 10000)
+	rand.Read(data)
+
+	for _, split := range []int{0, 1, 7, 4096, len(data)} {
+		a, b := data[:split], data[split:]
+
+		for _, algo := range []Hash32Algorithm{CRC32, CRC32C} {
+			got, err := algo.Combine(algo.HashBytes(a), algo.HashBytes(b), int64(len(b)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := algo.HashBytes(data); got != want {
+				t.Fatalf("%s split at %d: expected %08x, got %08x", algo, split, want, got)
+			}
+		}
+
+		for _, algo := range []Hash64Algorithm{CRC64, CRC64_ECMA, CRC64_NVME} {
+			got, err := algo.Combine(algo.HashBytes(a), algo.HashBytes(b), int64(len(b)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := algo.HashBytes(data); got != want {
+				t.Fatalf("%s split at %d: expected %016x, got %016x", algo, split, want, got)
+			}
+		}
+	}
+
+	if _, err := FNV32.Combine(1, 2, 3); err != ErrUnsupported {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+	if _, err := FNV64.Combine(1, 2, 3); err != ErrUnsupported {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+
+	// An empty second part leaves the first CRC unchanged; a negative one is an error.
+	crc := CRC32C.HashBytes([]byte("abc"))
+	if got, err := CRC32C.Combine(crc, CRC32C.HashBytes(nil), 0); err != nil || got != crc {
+		t.Fatalf("expected %08x, got %08x, %v", crc, got, err)
+	}
+	if _, err := CRC32C.Combine(crc, 0, -1); err != ErrNegativeLength {
+		t.Fatalf("expected ErrNegativeLength, got %v", err)
+	}
+	if _, err := CRC64.Combine(1, 2, -1); err != ErrNegativeLength {
+		t.Fatalf("expected ErrNegativeLength, got %v", err)
+	}
+}
+
+func BenchmarkCRC64(b *testing.B) {
+	data := make([]byte, 4096)
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		CRC64.HashBytes(data)
+	}
+}
@@ -0,0 +1,197 @@
+package hashx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash"
+	"io"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/dchest/siphash"
+	"github.com/twmb/murmur3"
+	"github.com/zeebo/xxh3"
+)
+
+type Hash128Algorithm string
+
+const (
+	// XXH64 is the same function as XXHash, as a Hash64Algorithm
+	XXH64    Hash64Algorithm  = "xxh64"
+	XXH3_64  Hash64Algorithm  = "xxh3-64"
+	XXH3_128 Hash128Algorithm = "xxh3-128"
+	WyHash   Hash64Algorithm  = "wyhash"
+
+	Murmur3_32  Hash32Algorithm  = "murmur3-32"
+	Murmur3_128 Hash128Algorithm = "murmur3-128"
+
+	// Keyed, with a 16 byte key
+	SipHash_2_4 KeyedHashAlgorithm = "siphash-2-4"
+)
+
+func init() {
+	RegisterSeeded(XXH64, func(seed uint64) hash.Hash {
+		return xxhash.NewWithSeed(seed)
+	})
+	setSum64(XXH64, xxhash.Sum64)
+	RegisterSeeded(XXH3_64, func(seed uint64) hash.Hash {
+		return xxh3.NewSeed(seed)
+	})
+	setSum64(XXH3_64, xxh3.Hash)
+	RegisterSeeded(XXH3_128, func(seed uint64) hash.Hash {
+		return xxh3128{xxh3.NewSeed(seed)}
+	})
+	RegisterSeeded(WyHash, func(seed uint64) hash.Hash {
+		return newWyhash(seed)
+	})
+	setSum64(WyHash, func(p []byte) uint64 {
+		return wyhashSum(p, 0)
+	})
+	RegisterSeeded(Murmur3_32, func(seed uint64) hash.Hash {
+		return murmur3.SeedNew32(uint32(seed))
+	})
+	setSum32(Murmur3_32, murmur3.Sum32)
+	RegisterSeeded(Murmur3_128, func(seed uint64) hash.Hash {
+		return murmur3.SeedNew128(seed, seed)
+	})
+
+	RegisterKeyed(SipHash_2_4, func(key []byte) (func() hash.Hash, error) {
+		if len(key) != 16 {
+			return nil, ErrWrongKeyLength(16, len(key))
+		}
+		return func() hash.Hash {
+			return siphash.New(key)
+		}, nil
+	})
+}
+
+// xxh3128 exposes the 128-bit digest of an xxh3 hasher, which only sums 64 bits through hash.Hash.
+type xxh3128 struct {
+	*xxh3.Hasher
+}
+
+func (h xxh3128) Sum(b []byte) []byte {
+	sum := h.Sum128()
+	b = binary.BigEndian.AppendUint64(b, sum.Hi)
+	return binary.BigEndian.AppendUint64(b, sum.Lo)
+}
+
+func (h xxh3128) Size() int {
+	return 16
+}
+
+// Uint128 is a 128-bit hash value. Hi holds the first 8 bytes of the digest.
+type Uint128 struct {
+	Hi, Lo uint64
+}
+
+func uint128(digest []byte) Uint128 {
+	return Uint128{
+		Hi: binary.BigEndian.Uint64(digest),
+		Lo: binary.BigEndian.Uint64(digest[8:]),
+	}
+}
+
+// HashBytes hashes data. It returns the zero value if the algorithm is not a
+// registered 128-bit algorithm; use Sum128 or ParseHash128Algorithm to detect that.
+func (algo128 Hash128Algorithm) HashBytes(data []byte) Uint128 {
+	v, _ := algo128.Sum128(data)
+	return v
+}
+
+// HashString hashes str without copying it.
+func (algo128 Hash128Algorithm) HashString(str string) Uint128 {
+	v, _ := algo128.Sum128String(str)
+	return v
+}
+
+// Sum128 hashes data, or returns an error if the algorithm is not a registered
+// 128-bit algorithm.
+func (algo128 Hash128Algorithm) Sum128(data []byte) (Uint128, error) {
+	entry, err := lookupClass(string(algo128), ClassHash128)
+	if err != nil {
+		return Uint128{}, err
+	}
+	h := entry.newHash()
+	h.Write(data)
+	return uint128(h.Sum(nil)), nil
+}
+
+// Sum128String is Sum128 for a string, without copying it.
+func (algo128 Hash128Algorithm) Sum128String(str string) (Uint128, error) {
+	return algo128.Sum128(stringBytes(str))
+}
+
+func (algo128 Hash128Algorithm) HashReader(r io.Reader) (Uint128, error) {
+	entry, err := lookupClass(string(algo128), ClassHash128)
+	if err != nil {
+		return Uint128{}, err
+	}
+	return sum128(entry.newHash(), r)
+}
+
+// HashBytesSeed hashes data with a seeded variant of the algorithm.
+func (algo128 Hash128Algorithm) HashBytesSeed(data []byte, seed uint64) (Uint128, error) {
+	fn, err := getSeededFunc(string(algo128), ClassHash128)
+	if err != nil {
+		return Uint128{}, err
+	}
+	return sum128(fn(seed), bytes.NewReader(data))
+}
+
+func sum128(h hash.Hash, r io.Reader) (Uint128, error) {
+	if _, err := io.Copy(h, r); err != nil {
+		return Uint128{}, err
+	}
+	return uint128(h.Sum(nil)), nil
+}
+
+// HashBytesSeed hashes data with a seeded variant of the algorithm, for example to
+// derive independent hash functions for a Bloom filter. Algorithms with a 32-bit
+// seed use its low 32 bits.
+func (algo32 Hash32Algorithm) HashBytesSeed(data []byte, seed uint64) (uint32, error) {
+	fn, err := getSeededFunc(string(algo32), ClassHash32)
+	if err != nil {
+		return 0, err
+	}
+	h := fn(seed)
+	h.Write(data)
+	return binary.BigEndian.Uint32(h.Sum(nil)), nil
+}
+
+// HashBytesSeed hashes data with a seeded variant of the algorithm, for example to
+// derive independent hash functions for a Bloom filter.
+func (algo64 Hash64Algorithm) HashBytesSeed(data []byte, seed uint64) (uint64, error) {
+	fn, err := getSeededFunc(string(algo64), ClassHash64)
+	if err != nil {
+		return 0, err
+	}
+	h := fn(seed)
+	h.Write(data)
+	return binary.BigEndian.Uint64(h.Sum(nil)), nil
+}
+
+// GetSeededHash returns a constructor for a seeded variant of algo.
+// It returns ErrUnsupported for algorithms that do not take a seed.
+func GetSeededHash[T Hash32Algorithm | Hash64Algorithm | Hash128Algorithm](algo T, seed uint64) (func() hash.Hash, error) {
+	entry, ok := lookup(string(algo))
+	if !ok || entry.newSeeded == nil {
+		return nil, ErrUnsupported
+	}
+	return func() hash.Hash {
+		return entry.newSeeded(seed)
+	}, nil
+}
+
+// IsSeeded reports whether algo has seeded variants.
+func IsSeeded[T AnyHashAlgorithm](algo T) bool {
+	entry, ok := lookup(string(algo))
+	return ok && entry.newSeeded != nil
+}
+
+func getSeededFunc(name string, class Class) (func(seed uint64) hash.Hash, error) {
+	entry, ok := lookup(name)
+	if !ok || entry.class != class || entry.newSeeded == nil {
+		return nil, ErrUnsupported
+	}
+	return entry.newSeeded, nil
+}
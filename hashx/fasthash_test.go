@@ -0,0 +1,137 @@
+package hashx
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/zeebo/xxh3"
+)
+
+// Seeded vectors from the wyhash reference test suite, where the seed is the index.
+func TestWyhashVectors(t *testing.T) {
+	for seed, tt := range []struct {
+		input string
+		want  uint64
+	}{
+		{"", 0x0409638ee2bde459},
+		{"a", 0xa8412d091b5fe0a9},
+		{"abc", 0x32dd92e4b2915153},
+		{"message digest", 0x8619124089a3a16b},
+		{"abcdefghijklmnopqrstuvwxyz", 0x7a43afb61d7f5f40},
+		{"ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789", 0xff42329b90e50d58},
+		{"12345678901234567890123456789012345678901234567890123456789012345678901234567890", 0xc39cab13b115aad3},
+	} {
+		got, err := WyHash.HashBytesSeed([]byte(tt.input), uint64(seed))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Fatalf("wyhash(%q, %d): expected %016x, got %016x", tt.input, seed, tt.want, got)
+		}
+	}
+}
+
+func TestSeededHashes(t *testing.T) {
+	data := make([]byte, 1000)
+	rand.Read(data)
+
+	for _, algo := range []Hash64Algorithm{XXH64, XXH3_64, WyHash} {
+		seeded, err := algo.HashBytesSeed(data, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seeded != algo.HashBytes(data) {
+			t.Fatalf("%s: seed 0 should match the unseeded hash", algo)
+		}
+		other, _ := algo.HashBytesSeed(data, 42)
+		if other == seeded {
+			t.Fatalf("%s: different seeds gave the same hash", algo)
+		}
+	}
+
+	got, _ := XXH3_64.HashBytesSeed(data, 42)
+	if want := xxh3.HashSeed(data, 42); got != want {
+		t.Fatalf("expected %016x, got %016x", want, got)
+	}
+	got128, _ := XXH3_128.HashBytesSeed(data, 42)
+	if want := xxh3.Hash128Seed(data, 42); got128 != (Uint128{Hi: want.Hi, Lo: want.Lo}) {
+		t.Fatalf("expected %+v, got %+v", want, got128)
+	}
+
+	if m, _ := Murmur3_32.HashBytesSeed([]byte("Hello, world!"), 1234); m != 0xfaf6cdb3 {
+		t.Fatalf("expected faf6cdb3, got %08x", m)
+	}
+	if XXH64.HashString("abc") != xxhashUint64("abc") {
+		t.Fatal("XXH64 should match XXHash")
+	}
+
+	if _, err := FNV64.HashBytesSeed(data, 1); err != ErrUnsupported {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+	if _, err := GetSeededHash(CRC32, 1); err != ErrUnsupported {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+	if !IsSeeded(Murmur3_128) || IsSeeded(SHA256) {
+		t.Fatal("IsSeeded reported the wrong algorithms")
+	}
+}
+
+func xxhashUint64(s string) uint64 {
+	sum, _ := HashString(XXHash, s)
+	return sum.Uint64()
+}
+
+func TestHash128(t *testing.T) {
+	data := make([]byte, 5000)
+	rand.Read(data)
+
+	for _, algo := range []Hash128Algorithm{XXH3_128, Murmur3_128} {
+		want := algo.HashBytes(data)
+		got, err := algo.HashReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Fatalf("%s: reader and bytes disagree", algo)
+		}
+
+		sum, _ := HashBytes(algo, data)
+		if uint128(sum.data) != want || sum.Uint64() != want.Hi {
+			t.Fatalf("%s: HashSum and Uint128 disagree", algo)
+		}
+		if class, _ := ClassOf(algo); class != ClassHash128 {
+			t.Fatalf("%s registered as %s", algo, class)
+		}
+	}
+
+	if _, err := Hash128Algorithm("sha256").HashReader(bytes.NewReader(data)); err != ErrUnsupported {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+	if _, err := HashString(SipHash_2_4, "x", make([]byte, 8)); err == nil {
+		t.Fatal("expected a key length error")
+	}
+}
+
+func TestStreamingFastHashes(t *testing.T) {
+	data := make([]byte, 300)
+	rand.Read(data)
+
+	for _, algo := range []string{string(XXH3_64), string(XXH3_128), string(WyHash), string(Murmur3_32), string(Murmur3_128)} {
+		fn, _ := GetHash(algo)
+		h := fn()
+		h.Write(data[:7])
+		h.Write(data[7:100])
+		h.Write(data[100:])
+		whole, _ := HashBytes(algo, data)
+		if !bytes.Equal(h.Sum(nil), whole.data) {
+			t.Fatalf("%s: split writes differ from a single write", algo)
+		}
+
+		h.Reset()
+		h.Write(data)
+		if !bytes.Equal(h.Sum(nil), whole.data) {
+			t.Fatalf("%s: Reset did not clear the state", algo)
+		}
+	}
+}
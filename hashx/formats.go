@@ -0,0 +1,206 @@
+package hashx
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// Multihash codes from the multiformats multicodec table.
+var multihashCodes = map[string]uint64{
+	string(SHA1):     0x11,
+	string(SHA256):   0x12,
+	string(SHA512):   0x13,
+	string(SHA3_512): 0x14,
+	string(SHA3_384): 0x15,
+	string(SHA3_256): 0x16,
+	string(SHA3_224): 0x17,
+	string(SHA384):   0x20,
+	string(MD4):      0xd4,
+	string(MD5):      0xd5,
+	string(SHA224):   0x1013,
+}
+
+// Hash algorithm names from the IANA Named Information Hash Algorithm Registry (RFC 6920).
+var niNames = map[string]string{
+	string(SHA256):   "sha-256",
+	string(SHA384):   "sha-384",
+	string(SHA512):   "sha-512",
+	string(SHA3_224): "sha3-224",
+	string(SHA3_256): "sha3-256",
+	string(SHA3_384): "sha3-384",
+	string(SHA3_512): "sha3-512",
+}
+
+// Subresource Integrity only allows the SHA-2 family.
+var sriAlgorithms = map[string]bool{
+	string(SHA256): true,
+	string(SHA384): true,
+	string(SHA512): true,
+}
+
+// RegisterMultihashCode maps an algorithm to its multicodec code so it can be used with Multihash and DecodeMultihash.
+// It panics if either the algorithm or the code is already mapped.
+func RegisterMultihashCode[T AnyHashAlgorithm](algo T, code uint64) {
+	name := string(normalizeAlgName[HashAlgorithm](string(algo)))
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, exists := multihashCodes[name]; exists {
+		panic(fmt.Sprintf("hashx: multihash code for %q already registered", name))
+	}
+	for other, c := range multihashCodes {
+		if c == code {
+			panic(fmt.Sprintf("hashx: multihash code %#x already registered for %q", code, other))
+		}
+	}
+	multihashCodes[name] = code
+}
+
+func multihashCode(algo string) (uint64, bool) {
+	registryMu.RLock()
+	code, ok := multihashCodes[string(normalizeAlgName[HashAlgorithm](algo))]
+	registryMu.RUnlock()
+	return code, ok
+}
+
+func multihashAlgorithm(code uint64) (string, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	for name, c := range multihashCodes {
+		if c == code {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// Multihash encodes the sum as a multiformats multihash: varint code, varint length, digest.
+func (h HashSum) Multihash() ([]byte, error) {
+	code, ok := multihashCode(h.algo)
+	if !ok {
+		return nil, &UnknownAlgorithmError{Algorithm: h.algo}
+	}
+
+	buf := make([]byte, 0, 2*binary.MaxVarintLen64+len(h.data))
+	buf = binary.AppendUvarint(buf, code)
+	buf = binary.AppendUvarint(buf, uint64(len(h.data)))
+	return append(buf, h.data...), nil
+}
+
+// DecodeMultihash parses a binary multihash back into a HashSum.
+func DecodeMultihash(buf []byte) (*HashSum, error) {
+	code, n := binary.Uvarint(buf)
+	if n <= 0 {
+		return nil, fmt.Errorf("%w: bad multihash code", ErrInvalidEncoding)
+	}
+	buf = buf[n:]
+
+	length, n := binary.Uvarint(buf)
+	if n <= 0 {
+		return nil, fmt.Errorf("%w: bad multihash length", ErrInvalidEncoding)
+	}
+	buf = buf[n:]
+
+	if uint64(len(buf)) != length {
+		return nil, fmt.Errorf("%w: multihash declares %d digest bytes but has %d", ErrInvalidEncoding, length, len(buf))
+	}
+
+	algo, ok := multihashAlgorithm(code)
+	if !ok {
+		return nil, &UnknownAlgorithmError{Algorithm: fmt.Sprintf("multihash:%#x", code)}
+	}
+
+	return newHashSum(algo, append([]byte(nil), buf...))
+}
+
+// SRI encodes the sum as a Subresource Integrity value such as sha256-<base64>.
+func (h HashSum) SRI() (string, error) {
+	algo := string(normalizeAlgName[HashAlgorithm](h.algo))
+	if !sriAlgorithms[algo] {
+		return "", &UnknownAlgorithmError{Algorithm: h.algo}
+	}
+	return algo + "-" + base64.StdEncoding.EncodeToString(h.data), nil
+}
+
+// ParseSRI parses a Subresource Integrity attribute value.
+// Every recognised entry is returned; entries for algorithms SRI does not allow are skipped as the spec requires.
+func ParseSRI(integrity string) ([]*HashSum, error) {
+	var sums []*HashSum
+	for _, token := range strings.Fields(integrity) {
+		token, _, _ = strings.Cut(token, "?")
+		algo, digest, ok := strings.Cut(token, "-")
+		if !ok || !sriAlgorithms[algo] {
+			continue
+		}
+
+		data, err := base64.StdEncoding.DecodeString(digest)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidEncoding, err)
+		}
+
+		sum, err := newHashSum(algo, data)
+		if err != nil {
+			return nil, err
+		}
+		sums = append(sums, sum)
+	}
+
+	if len(sums) == 0 {
+		return nil, fmt.Errorf("%w: no supported integrity metadata", ErrInvalidEncoding)
+	}
+	return sums, nil
+}
+
+// NI encodes the sum as an RFC 6920 named information URI, ni://authority/alg;digest.
+// The authority is optional.
+func (h HashSum) NI(authority ...string) (string, error) {
+	name, ok := niNames[string(normalizeAlgName[HashAlgorithm](h.algo))]
+	if !ok {
+		return "", &UnknownAlgorithmError{Algorithm: h.algo}
+	}
+
+	auth := ""
+	if len(authority) > 0 {
+		auth = authority[0]
+	}
+	return "ni://" + auth + "/" + name + ";" + base64.RawURLEncoding.EncodeToString(h.data), nil
+}
+
+// ParseNI parses an RFC 6920 ni URI. The authority and any query parameters are ignored.
+func ParseNI(uri string) (*HashSum, error) {
+	rest, ok := strings.CutPrefix(uri, "ni://")
+	if !ok {
+		return nil, fmt.Errorf("%w: missing ni:// scheme", ErrInvalidEncoding)
+	}
+
+	_, rest, ok = strings.Cut(rest, "/")
+	if !ok {
+		return nil, fmt.Errorf("%w: missing ni path", ErrInvalidEncoding)
+	}
+	rest, _, _ = strings.Cut(rest, "?")
+
+	name, digest, ok := strings.Cut(rest, ";")
+	if !ok || digest == "" {
+		return nil, fmt.Errorf("%w: missing ni digest", ErrInvalidEncoding)
+	}
+
+	algo := ""
+	for a, n := range niNames {
+		if strings.EqualFold(n, name) {
+			algo = a
+			break
+		}
+	}
+	if algo == "" {
+		return nil, &UnknownAlgorithmError{Algorithm: name}
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(digest)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEncoding, err)
+	}
+
+	return newHashSum(algo, data)
+}
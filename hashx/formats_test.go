@@ -0,0 +1,105 @@
+package hashx
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestMultihash(t *testing.T) {
+	sum, err := HashString(SHA256, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mh, err := sum.Multihash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "1220ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+	if got := hex.EncodeToString(mh); got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+
+	decoded, err := DecodeMultihash(mh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Encode() != sum.Encode() {
+		t.Fatalf("round trip mismatch: %s", decoded.Encode())
+	}
+
+	if _, err := DecodeMultihash(append(mh, 0)); !errors.Is(err, ErrInvalidEncoding) {
+		t.Fatalf("expected trailing byte to be rejected, got %v", err)
+	}
+	if _, err := DecodeMultihash([]byte{0x80}); !errors.Is(err, ErrInvalidEncoding) {
+		t.Fatalf("expected truncated varint to be rejected, got %v", err)
+	}
+
+	var unknown *UnknownAlgorithmError
+	fnv, _ := HashString(FNV64, "abc")
+	if _, err := fnv.Multihash(); !errors.As(err, &unknown) {
+		t.Fatalf("expected UnknownAlgorithmError, got %v", err)
+	}
+}
+
+func TestSRI(t *testing.T) {
+	sum, err := HashString(SHA256, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sri, err := sum.SRI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "sha256-ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0="; sri != want {
+		t.Fatalf("expected %s, got %s", want, sri)
+	}
+
+	sums, err := ParseSRI("md5-abcd " + sri + "?ct=text/plain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sums) != 1 || sums[0].Encode() != sum.Encode() {
+		t.Fatalf("unexpected sums %v", sums)
+	}
+
+	if _, err := ParseSRI("md5-abcd"); !errors.Is(err, ErrInvalidEncoding) {
+		t.Fatalf("expected ErrInvalidEncoding, got %v", err)
+	}
+}
+
+func TestNI(t *testing.T) {
+	// Example from RFC 6920 section 8.1.
+	sum, err := HashString(SHA256, "Hello World!")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ni, err := sum.NI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "ni:///sha-256;f4OxZX_x_FO5LcGBSKHWXfwtSx-j1ncoSt3SABJtkGk"; ni != want {
+		t.Fatalf("expected %s, got %s", want, ni)
+	}
+
+	withAuth, err := sum.NI("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := ParseNI(withAuth + "?ct=text/plain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Encode() != sum.Encode() {
+		t.Fatalf("round trip mismatch: %s", parsed.Encode())
+	}
+
+	var unknown *UnknownAlgorithmError
+	if _, err := ParseNI("ni:///sha-256-32;f4OxZQ"); !errors.As(err, &unknown) {
+		t.Fatalf("expected UnknownAlgorithmError, got %v", err)
+	}
+}
@@ -14,7 +14,6 @@ import (
 	"hash/crc64"
 	"hash/fnv"
 	"io"
-	"reflect"
 	"strings"
 
 	"github.com/cespare/xxhash/v2"
@@ -27,87 +26,136 @@ import (
 	"golang.org/x/crypto/sha3"
 )
 
-var hashMap =  make(map[string]reflect.Type)
-
 type HashAlgorithm string
 type Hash32Algorithm string
 type Hash64Algorithm string
 type KeyedHashAlgorithm string
 
-var hashAlgorithmType = reflect.TypeOf(HashAlgorithm(""))
-var hash32AlgorithmType = reflect.TypeOf(Hash32Algorithm(""))
-var hash64AlgorithmType = reflect.TypeOf(Hash64Algorithm(""))
-var keyedHashAlgorithmType = reflect.TypeOf(KeyedHashAlgorithm(""))
-
 func init() {
 	// HashAlgorithm
-	hashMap[string(SHA1)] = hashAlgorithmType
-	hashMap[string(SHA224)] = hashAlgorithmType
-	hashMap[string(SHA256)] = hashAlgorithmType
-	hashMap[string(SHA384)] = hashAlgorithmType
-	hashMap[string(SHA512)] = hashAlgorithmType
-	hashMap[string(SHA3_224)] = hashAlgorithmType
-	hashMap[string(SHA3_256)] = hashAlgorithmType
-	hashMap[string(SHA3_384)] = hashAlgorithmType
-	hashMap[string(SHA3_512)] = hashAlgorithmType
-	hashMap[string(MD4)] = hashAlgorithmType
-	hashMap[string(MD5)] = hashAlgorithmType
-	hashMap[string(XXHash)] = hashAlgorithmType
+	Register(SHA1, sha1.New)
+	Register(SHA224, sha256.New224)
+	Register(SHA256, sha256.New)
+	Register(SHA384, sha512.New384)
+	Register(SHA512, sha512.New)
+	Register(SHA3_224, sha3.New224)
+	Register(SHA3_256, sha3.New256)
+	Register(SHA3_384, sha3.New384)
+	Register(SHA3_512, sha3.New512)
+	Register(MD4, md4.New)
+	Register(MD5, md5.New)
+	Register(XXHash, func() hash.Hash {
+		return xxhash.New()
+	})
 
 	// KeyedHashAlgorithm
-	hashMap[string(Blake2s)] = keyedHashAlgorithmType
-	hashMap[string(Blake2b)] = keyedHashAlgorithmType
-	hashMap[string(Blake3)] = keyedHashAlgorithmType
-	hashMap[string(Highway)] = keyedHashAlgorithmType
-	hashMap[string(HMAC_SHA256)] = keyedHashAlgorithmType
-	hashMap[string(HMAC_SHA512)] = keyedHashAlgorithmType
+	RegisterKeyed(Blake2s, func(key []byte) (func() hash.Hash, error) {
+		if len(key) > 64 {
+			return nil, ErrWrongKeyLength(64, len(key))
+		}
+		return func() hash.Hash {
+			h, _ := blake2s.New256(key)
+			return h
+		}, nil
+	})
+	RegisterKeyed(Blake2b, func(key []byte) (func() hash.Hash, error) {
+		if len(key) > 64 {
+			return nil, ErrWrongKeyLength(64, len(key))
+		}
+		return func() hash.Hash {
+			h, _ := blake2b.New512(key)
+			return h
+		}, nil
+	})
+	RegisterKeyed(Blake3, func(key []byte) (func() hash.Hash, error) {
+		if len(key) != 32 {
+			return nil, ErrWrongKeyLength(32, len(key))
+		}
+		return func() hash.Hash {
+			h, _ := blake3.NewKeyed(key)
+			return h
+		}, nil
+	})
+	RegisterKeyed(Highway, func(key []byte) (func() hash.Hash, error) {
+		if len(key) != 32 {
+			return nil, ErrWrongKeyLength(32, len(key))
+		}
+		return func() hash.Hash {
+			h, _ := highwayhash.New(key)
+			return h
+		}, nil
+	})
+	RegisterKeyed(HMAC_SHA256, func(key []byte) (func() hash.Hash, error) {
+		return func() hash.Hash {
+			return hmac.New(sha256.New, key)
+		}, nil
+	})
+	RegisterKeyed(HMAC_SHA512, func(key []byte) (func() hash.Hash, error) {
+		return func() hash.Hash {
+			return hmac.New(sha512.New, key)
+		}, nil
+	})
 
 	// Hash32Algorithm
-	hashMap[string(CRC32)] = hash32AlgorithmType
-	hashMap[string(FNV32)] = hash32AlgorithmType
+	Register32(CRC32, crc32.NewIEEE)
+	setSum32(CRC32, crc32.ChecksumIEEE)
+	Register32(FNV32, fnv.New32a)
+	setSum32(FNV32, fnv32a)
 
 	// Hash64Algorithm
-	hashMap[string(CRC64)] = hash64AlgorithmType
-	hashMap[string(FNV64)] = hash64AlgorithmType
+	Register64(CRC64, func() hash.Hash64 {
+		return crc64.New(crc64ISOTable)
+	})
+	setSum64(CRC64, func(p []byte) uint64 {
+		return crc64.Checksum(p, crc64ISOTable)
+	})
+	Register64(FNV64, fnv.New64a)
+	setSum64(FNV64, fnv64a)
 }
 
-
 const (
 	// SHA Family
-	SHA1      HashAlgorithm = "sha1"
-	SHA224    HashAlgorithm = "sha224"
-	SHA256    HashAlgorithm = "sha256"
-	SHA384    HashAlgorithm = "sha384"
-	SHA512    HashAlgorithm = "sha512"
-	SHA3_224  HashAlgorithm = "sha3-224"
-	SHA3_256  HashAlgorithm = "sha3-256"
-	SHA3_384  HashAlgorithm = "sha3-384"
-	SHA3_512  HashAlgorithm = "sha3-512"
+	SHA1     HashAlgorithm = "sha1"
+	SHA224   HashAlgorithm = "sha224"
+	SHA256   HashAlgorithm = "sha256"
+	SHA384   HashAlgorithm = "sha384"
+	SHA512   HashAlgorithm = "sha512"
+	SHA3_224 HashAlgorithm = "sha3-224"
+	SHA3_256 HashAlgorithm = "sha3-256"
+	SHA3_384 HashAlgorithm = "sha3-384"
+	SHA3_512 HashAlgorithm = "sha3-512"
 
 	// MD Family
-	MD4  HashAlgorithm = "md4"
-	MD5  HashAlgorithm = "md5"
+	MD4 HashAlgorithm = "md4"
+	MD5 HashAlgorithm = "md5"
 
 	// Blake Family, keyed
 	Blake2s KeyedHashAlgorithm = "blake2s"
 	// Blake Family, keyed
 	Blake2b KeyedHashAlgorithm = "blake2b"
 	// Blake Family, keyed
-	Blake3  KeyedHashAlgorithm = "blake3"
+	Blake3 KeyedHashAlgorithm = "blake3"
 
 	// Others
-	CRC32       Hash32Algorithm = "crc32"
-	CRC64       Hash64Algorithm = "crc64"
-	FNV32       Hash32Algorithm = "fnv32"
-	FNV64       Hash64Algorithm = "fnv64"
-	XXHash      HashAlgorithm = "xxhash"
-	
+	CRC32  Hash32Algorithm = "crc32"
+	CRC64  Hash64Algorithm = "crc64"
+	FNV32  Hash32Algorithm = "fnv32"
+	FNV64  Hash64Algorithm = "fnv64"
+	XXHash HashAlgorithm   = "xxhash" // XXH64 as a byte digest, see XXH64 for a Hash64Algorithm
+
+	// CRC-32C (Castagnoli), hardware accelerated where the CPU supports it
+	CRC32C Hash32Algorithm = "crc32c"
+	// CRC-64/XZ, the ECMA-182 polynomial
+	CRC64_ECMA Hash64Algorithm = "crc64-ecma"
+	// CRC-64/NVME, as used by NVMe and S3 checksums
+	CRC64_NVME Hash64Algorithm = "crc64-nvme"
+
 	// Keyed
 	HMAC_SHA256 KeyedHashAlgorithm = "hmac-sha256"
 	// Keyed
 	HMAC_SHA512 KeyedHashAlgorithm = "hmac-sha512"
 	// Keyed
-	Highway     KeyedHashAlgorithm = "highway"
+	Highway KeyedHashAlgorithm = "highway"
 )
 
 type HashSum struct {
@@ -117,6 +165,7 @@ type HashSum struct {
 
 var ErrUnsupported = errors.New("hashx: unsupported hashing algorithm")
 var ErrKeyRequired = errors.New("hashx: key is required")
+var ErrInvalidSize = errors.New("hashx: digest size must be positive")
 var ErrWrongKeyLength = func(desiredLen, len int) error {
 	return fmt.Errorf("hashx: key wrong length, expected: %v but go %v", desiredLen, len)
 }
@@ -160,213 +209,147 @@ func (h HashSum) Encode() string {
 	return fmt.Sprintf("%s:%s", h.algo, hex.EncodeToString(h.data))
 }
 
-func (algo32 Hash32Algorithm) HashBytes(data []byte) uint32 {
-	fn, _ := getHash32Func(algo32)
-
-
-	h := fn()
+// NewHashSum wraps a digest computed elsewhere, checking the algorithm is registered
+// and, where it has a fixed size, that the digest length matches.
+func NewHashSum[T AnyHashAlgorithm](algo T, digest []byte) (*HashSum, error) {
+	return newHashSum(string(algo), append([]byte(nil), digest...))
+}
 
-	h.Write(data)
+// Bytes returns a copy of the raw digest.
+func (h HashSum) Bytes() []byte {
+	return append([]byte(nil), h.data...)
+}
 
-	if h32, ok := h.(hash.Hash32); ok {
-		return h32.Sum32()
-	}
+// Algorithm returns the name of the algorithm that produced the digest.
+func (h HashSum) Algorithm() string {
+	return h.algo
+}
 
-	return 0
+// HashBytes hashes data. It returns 0 if the algorithm is not a registered
+// 32-bit algorithm; use Sum32 or ParseHash32Algorithm to detect that.
+func (algo32 Hash32Algorithm) HashBytes(data []byte) uint32 {
+	v, _ := algo32.Sum32(data)
+	return v
 }
 
+// HashString hashes str without copying it. Like HashBytes it returns 0 for
+// an unsupported algorithm.
 func (algo32 Hash32Algorithm) HashString(str string) uint32 {
-	return algo32.HashBytes([]byte(str))
+	v, _ := algo32.Sum32String(str)
+	return v
 }
 
-func (algo32 Hash32Algorithm) HashReader(r io.Reader) (uint32, error) {
-	fn, err := getHash32Func(algo32)
-
+// Sum32 hashes data, or returns an error if the algorithm is not a registered
+// 32-bit algorithm. Common algorithms such as CRC32 and FNV32 do not allocate.
+func (algo32 Hash32Algorithm) Sum32(data []byte) (uint32, error) {
+	entry, err := lookupClass(string(algo32), ClassHash32)
 	if err != nil {
 		return 0, err
 	}
+	if entry.sum32 != nil {
+		return entry.sum32(data), nil
+	}
 
-	h := fn()
+	h := entry.newHash()
+	h.Write(data)
+	return hashValue32(h), nil
+}
 
-	_, err = io.Copy(h, r)
+// Sum32String is Sum32 for a string, without copying it.
+func (algo32 Hash32Algorithm) Sum32String(str string) (uint32, error) {
+	return algo32.Sum32(stringBytes(str))
+}
+
+func (algo32 Hash32Algorithm) HashReader(r io.Reader) (uint32, error) {
+	entry, err := lookupClass(string(algo32), ClassHash32)
 	if err != nil {
 		return 0, err
 	}
 
-	if h32, ok := h.(hash.Hash32); ok {
-		return h32.Sum32(), nil
+	h := entry.newHash()
+	if _, err := io.Copy(h, r); err != nil {
+		return 0, err
 	}
-
-	return 0, ErrUnsupported
+	return hashValue32(h), nil
 }
 
+// HashBytes hashes data. It returns 0 if the algorithm is not a registered
+// 64-bit algorithm; use Sum64 or ParseHash64Algorithm to detect that.
 func (algo64 Hash64Algorithm) HashBytes(data []byte) uint64 {
-	fn, _ := getHash64Func(algo64)
-
-	h := fn()
+	v, _ := algo64.Sum64(data)
+	return v
+}
 
-	h.Write(data)
+// HashString hashes str without copying it. Like HashBytes it returns 0 for
+// an unsupported algorithm.
+func (algo64 Hash64Algorithm) HashString(str string) uint64 {
+	v, _ := algo64.Sum64String(str)
+	return v
+}
 
-	if h32, ok := h.(hash.Hash64); ok {
-		return h32.Sum64()
+// Sum64 hashes data, or returns an error if the algorithm is not a registered
+// 64-bit algorithm. Common algorithms such as XXH64, XXH3_64, CRC64 and FNV64 do not allocate.
+func (algo64 Hash64Algorithm) Sum64(data []byte) (uint64, error) {
+	entry, err := lookupClass(string(algo64), ClassHash64)
+	if err != nil {
+		return 0, err
+	}
+	if entry.sum64 != nil {
+		return entry.sum64(data), nil
 	}
 
-	return 0
+	h := entry.newHash()
+	h.Write(data)
+	return hashValue64(h), nil
 }
 
-func (algo64 Hash64Algorithm) HashString(str string) uint64 {
-	return algo64.HashBytes([]byte(str))
+// Sum64String is Sum64 for a string, without copying it.
+func (algo64 Hash64Algorithm) Sum64String(str string) (uint64, error) {
+	return algo64.Sum64(stringBytes(str))
 }
 
 func (algo64 Hash64Algorithm) HashReader(r io.Reader) (uint64, error) {
-	fn, _ := getHash64Func(algo64)
-
-	h := fn()
-
-	_, err := io.Copy(h, r)
+	entry, err := lookupClass(string(algo64), ClassHash64)
 	if err != nil {
 		return 0, err
 	}
 
-	if h32, ok := h.(hash.Hash64); ok {
-		return h32.Sum64(), nil
+	h := entry.newHash()
+	if _, err := io.Copy(h, r); err != nil {
+		return 0, err
 	}
-
-	return 0, ErrUnsupported
+	return hashValue64(h), nil
 }
 
-
 func GetHash[T AnyHashAlgorithm](algo T, key ...[]byte) (func() hash.Hash, error) {
-	algoStr := string(algo)
-	alg, ok := hashMap[algoStr]
+	entry, ok := lookup(string(algo))
 	if !ok {
 		return nil, ErrUnsupported
 	}
 
-	switch alg {
-	case hashAlgorithmType:
-		return getHashFunc(HashAlgorithm(algoStr))
-	case keyedHashAlgorithmType:
+	switch entry.class {
+	case ClassKeyed:
 		if len(key) == 0 || len(key[0]) == 0 {
 			return nil, ErrKeyRequired
 		}
-		return getKeyedHashFunc(KeyedHashAlgorithm(algoStr), key[0])
-	case hash32AlgorithmType:
-		return getHash32Func(Hash32Algorithm(algoStr))
-	case hash64AlgorithmType:
-		return getHash64Func(Hash64Algorithm(algoStr))
-	default:
-		return nil, ErrUnsupported
-	}
-}
-
-
-func getHashFunc(algo HashAlgorithm) (func() hash.Hash, error) {
-	switch normalizeAlgName[HashAlgorithm](string(algo)){
-	case SHA1:
-		return sha1.New, nil
-	case SHA224:
-		return sha256.New224, nil
-	case SHA256:
-		return sha256.New, nil
-	case SHA384:
-		return sha512.New384, nil
-	case SHA512:
-		return sha512.New, nil
-	case SHA3_224:
-		return sha3.New224, nil
-	case SHA3_384: 
-		return sha3.New384, nil
-	case SHA3_512:
-		return sha3.New512, nil
-	case MD4:
-		return md4.New, nil
-	case MD5:
-		return md5.New, nil
-	case XXHash:
-		return func() hash.Hash {
-			return xxhash.New()
-		}, nil
+		return entry.newKeyed(key[0])
+	case ClassPlain, ClassHash32, ClassHash64, ClassHash128, ClassXOF:
+		return entry.newHash, nil
 	default:
 		return nil, ErrUnsupported
 	}
 }
 
-func getKeyedHashFunc(algo KeyedHashAlgorithm, key []byte) (func() hash.Hash, error) {
-	switch normalizeAlgName[KeyedHashAlgorithm](string(algo)) {
-	case HMAC_SHA256:
-		return func() hash.Hash {
-			return hmac.New(sha256.New, key)
-		}, nil
-	case HMAC_SHA512:
-		return func() hash.Hash {
-			return hmac.New(sha512.New, key)
-		}, nil
-	case Blake2b:
-		if len(key) > 64 {
-			return nil, ErrWrongKeyLength(64, len(key))
-		}
-		return func() hash.Hash {
-			h, _ := blake2b.New512(key)
-			return h
-		}, nil
-	case Blake2s:
-		if len(key) > 64 {
-			return nil, ErrWrongKeyLength(64, len(key))
-		}
-		return func() hash.Hash {
-			h, _ := blake2s.New256(key)
-			return h
-		}, nil
-	case Blake3:
-			if len(key) != 32 {
-			return nil, ErrWrongKeyLength(32, len(key))
-		}
-		return func() hash.Hash {
-			h, _ := blake3.NewKeyed(key)
-			return h
-		}, nil
-	case Highway:
-			if len(key) != 32 {
-			return nil, ErrWrongKeyLength(32, len(key))
-		}
-		return func() hash.Hash {
-			h, _ := highwayhash.New(key)
-			return h
-		}, nil
-	default:
-		return nil, ErrUnsupported
-	}
+func getHash64Func(algo Hash64Algorithm) (func() hash.Hash, error) {
+	return getClassFunc(string(algo), ClassHash64)
 }
 
-func getHash32Func(algo Hash32Algorithm) (func() hash.Hash, error) {
-	switch normalizeAlgName[Hash32Algorithm](string(algo)) {
-	case FNV32:
-		return func() hash.Hash {
-			return hash.Hash(fnv.New32a())
-		}, nil
-	case CRC32:
-		return func() hash.Hash {
-			return hash.Hash(crc32.NewIEEE())
-		}, nil
-	default:
+func getClassFunc(name string, class Class) (func() hash.Hash, error) {
+	entry, ok := lookup(name)
+	if !ok || entry.class != class {
 		return nil, ErrUnsupported
 	}
-}
-
-func getHash64Func(algo Hash64Algorithm) (func() hash.Hash, error) {
-	switch normalizeAlgName[Hash64Algorithm](string(algo)) {
-	case FNV64:
-		return func() hash.Hash {
-			return fnv.New64a()
-		}, nil
-	case CRC64:
-		return func() hash.Hash {
-			return crc64.New(crc64.MakeTable(crc64.ISO))
-		}, nil
-	default:
-			return nil, ErrUnsupported
-	}
+	return entry.newHash, nil
 }
 
 type AnyHashAlgorithm interface {
@@ -378,15 +361,6 @@ func normalizeAlgName[T AnyHashAlgorithm](a string) T {
 }
 
 func IsKeyed[T AnyHashAlgorithm](algo T) bool {
-	alg, ok := hashMap[string(algo)]
-	if !ok {
-		return false
-	}
-
-	switch alg {
-	case keyedHashAlgorithmType:
-		return true
-	default:
-		return false
-	}
-}
\ No newline at end of file
+	class, ok := ClassOf(algo)
+	return ok && class == ClassKeyed
+}
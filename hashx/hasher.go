@@ -0,0 +1,142 @@
+package hashx
+
+import (
+	"bytes"
+	"encoding"
+	"errors"
+	"fmt"
+	"hash"
+)
+
+var ErrNotCheckpointable = errors.New("hashx: algorithm state cannot be checkpointed")
+var ErrInvalidCheckpoint = errors.New("hashx: invalid checkpoint")
+
+var checkpointMagic = []byte("hashx\x01")
+
+// Hasher hashes data incrementally with a single algorithm.
+// When the underlying hash implements encoding.BinaryMarshaler its state can be
+// checkpointed with MarshalBinary and resumed later, possibly in another process.
+type Hasher struct {
+	h    hash.Hash
+	algo string
+}
+
+// NewHasher creates a Hasher for any registered algorithm.
+func NewHasher[T AnyHashAlgorithm](algo T, key ...[]byte) (*Hasher, error) {
+	fn, err := GetHash(algo, key...)
+	if err != nil {
+		return nil, err
+	}
+	return &Hasher{
+		h:    fn(),
+		algo: string(algo),
+	}, nil
+}
+
+// ResumeHasher recreates a Hasher from a checkpoint produced by MarshalBinary.
+// Keyed algorithms must be given the same key they were created with.
+func ResumeHasher(checkpoint []byte, key ...[]byte) (*Hasher, error) {
+	algo, _, err := parseCheckpoint(checkpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := NewHasher(HashAlgorithm(algo), key...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.UnmarshalBinary(checkpoint); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func (h *Hasher) Write(p []byte) (int, error) {
+	return h.h.Write(p)
+}
+
+// Sum returns the digest of everything written so far without changing the state.
+func (h *Hasher) Sum() *HashSum {
+	return &HashSum{
+		data: h.h.Sum(nil),
+		algo: h.algo,
+	}
+}
+
+func (h *Hasher) Reset() {
+	h.h.Reset()
+}
+
+func (h *Hasher) Size() int {
+	return h.h.Size()
+}
+
+// Checkpointable reports whether the algorithm implements state marshaling.
+// Some implementations still refuse particular states, keyed BLAKE2 for example,
+// so MarshalBinary can return ErrNotCheckpointable even when this is true.
+func (h *Hasher) Checkpointable() bool {
+	_, m := h.h.(encoding.BinaryMarshaler)
+	_, u := h.h.(encoding.BinaryUnmarshaler)
+	return m && u
+}
+
+// MarshalBinary snapshots the hash state. The snapshot records the algorithm
+// name so that it cannot be resumed with a different algorithm.
+// It returns ErrNotCheckpointable if the algorithm does not support it.
+func (h *Hasher) MarshalBinary() ([]byte, error) {
+	m, ok := h.h.(encoding.BinaryMarshaler)
+	if !ok || !h.Checkpointable() {
+		return nil, fmt.Errorf("%w: %s", ErrNotCheckpointable, h.algo)
+	}
+
+	state, err := m.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrNotCheckpointable, h.algo, err)
+	}
+
+	name := normalizeAlgName[HashAlgorithm](h.algo)
+	if len(name) > 255 {
+		return nil, fmt.Errorf("%w: algorithm name too long: %s", ErrNotCheckpointable, h.algo)
+	}
+	buf := make([]byte, 0, len(checkpointMagic)+1+len(name)+len(state))
+	buf = append(buf, checkpointMagic...)
+	buf = append(buf, byte(len(name)))
+	buf = append(buf, name...)
+	return append(buf, state...), nil
+}
+
+// UnmarshalBinary restores a snapshot taken from a Hasher of the same algorithm.
+func (h *Hasher) UnmarshalBinary(checkpoint []byte) error {
+	u, ok := h.h.(encoding.BinaryUnmarshaler)
+	if !ok || !h.Checkpointable() {
+		return fmt.Errorf("%w: %s", ErrNotCheckpointable, h.algo)
+	}
+
+	algo, state, err := parseCheckpoint(checkpoint)
+	if err != nil {
+		return err
+	}
+	if algo != string(normalizeAlgName[HashAlgorithm](h.algo)) {
+		return fmt.Errorf("%w: checkpoint is for %s, not %s", ErrInvalidCheckpoint, algo, h.algo)
+	}
+
+	if err := u.UnmarshalBinary(state); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCheckpoint, err)
+	}
+	return nil
+}
+
+func parseCheckpoint(checkpoint []byte) (string, []byte, error) {
+	rest, ok := bytes.CutPrefix(checkpoint, checkpointMagic)
+	if !ok || len(rest) < 1 {
+		return "", nil, ErrInvalidCheckpoint
+	}
+
+	n := int(rest[0])
+	rest = rest[1:]
+	if n == 0 || len(rest) < n {
+		return "", nil, ErrInvalidCheckpoint
+	}
+	return string(rest[:n]), rest[n:], nil
+}
@@ -0,0 +1,92 @@
+package hashx
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHasherCheckpoint(t *testing.T) {
+	part1, part2 := []byte("first chunk of the upload, "), []byte("second chunk of the upload")
+
+	cases := []struct {
+		algo string
+		key  [][]byte
+	}{
+		{algo: string(SHA256)},
+		{algo: string(SHA512)},
+		{algo: string(MD5)},
+		{algo: string(FNV64)},
+		{algo: string(CRC32)},
+		{algo: string(XXHash)},
+		{algo: string(SHA3_256)},
+	}
+
+	for _, c := range cases {
+		t.Run(c.algo, func(t *testing.T) {
+			h, err := NewHasher(HashAlgorithm(c.algo), c.key...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !h.Checkpointable() {
+				t.Fatalf("%s should be checkpointable", c.algo)
+			}
+			h.Write(part1)
+
+			state, err := h.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resumed, err := ResumeHasher(state, c.key...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resumed.Write(part2)
+
+			want, err := HashBytes(HashAlgorithm(c.algo), append(part1, part2...), c.key...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := resumed.Sum(); got.Encode() != want.Encode() {
+				t.Fatalf("expected %s, got %s", want.Encode(), got.Encode())
+			}
+		})
+	}
+}
+
+func TestHasherNotCheckpointable(t *testing.T) {
+	h, err := NewHasher(Blake3, make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Checkpointable() {
+		t.Fatal("blake3 should not be checkpointable")
+	}
+	if _, err := h.MarshalBinary(); !errors.Is(err, ErrNotCheckpointable) {
+		t.Fatalf("expected ErrNotCheckpointable, got %v", err)
+	}
+
+	mac, err := NewHasher(Blake2b, make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mac.MarshalBinary(); !errors.Is(err, ErrNotCheckpointable) {
+		t.Fatalf("expected ErrNotCheckpointable, got %v", err)
+	}
+}
+
+func TestHasherWrongCheckpoint(t *testing.T) {
+	h, _ := NewHasher(SHA256)
+	state, err := h.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, _ := NewHasher(SHA512)
+	if err := other.UnmarshalBinary(state); !errors.Is(err, ErrInvalidCheckpoint) {
+		t.Fatalf("expected ErrInvalidCheckpoint, got %v", err)
+	}
+	if _, err := ResumeHasher([]byte("garbage")); !errors.Is(err, ErrInvalidCheckpoint) {
+		t.Fatalf("expected ErrInvalidCheckpoint, got %v", err)
+	}
+}
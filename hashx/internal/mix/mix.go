@@ -0,0 +1,15 @@
+// Package mix holds the finalizer shared by the placement, bucketing and sketch
+// code in hashx.
+package mix
+
+// Mix64 is the SplitMix64 finalizer, a bijection that spreads every input bit over
+// all 64 output bits. It is applied to hash values so weak hashes such as FNV and
+// CRC still spread evenly.
+func Mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
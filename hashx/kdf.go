@@ -0,0 +1,481 @@
+package hashx
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+type KDFAlgorithm string
+
+const (
+	// Password hashing
+	Argon2id KDFAlgorithm = "argon2id"
+	// Password hashing
+	Scrypt KDFAlgorithm = "scrypt"
+	// Password hashing, encoded in the bcrypt $2a$ format rather than PHC
+	Bcrypt KDFAlgorithm = "bcrypt"
+	// Password hashing
+	PBKDF2_SHA256 KDFAlgorithm = "pbkdf2-sha256"
+	// Password hashing
+	PBKDF2_SHA512 KDFAlgorithm = "pbkdf2-sha512"
+
+	// Key derivation only
+	HKDF_SHA256 KDFAlgorithm = "hkdf-sha256"
+	// Key derivation only
+	HKDF_SHA512 KDFAlgorithm = "hkdf-sha512"
+)
+
+var ErrInvalidParams = errors.New("hashx: invalid kdf parameters")
+var ErrInvalidPasswordHash = errors.New("hashx: invalid password hash encoding")
+
+// KDFParams tunes a KDFAlgorithm. Each algorithm reads only the fields it uses and
+// zero fields take the algorithm's defaults, see DefaultKDFParams.
+type KDFParams struct {
+	// Iterations is the Argon2 time cost or the number of PBKDF2 rounds.
+	Iterations int
+	// Memory is the Argon2 memory cost in KiB.
+	Memory int
+	// Parallelism is the Argon2 lane count or the scrypt p parameter.
+	Parallelism int
+	// Cost is log2 of the scrypt N parameter or the bcrypt cost.
+	Cost int
+	// BlockSize is the scrypt r parameter.
+	BlockSize int
+	// SaltLength is the size of the random salt generated by HashPassword.
+	SaltLength int
+	// KeyLength is the size of the derived key.
+	KeyLength int
+	// Info is the HKDF context and application specific information.
+	Info []byte
+}
+
+type kdfFunc func(secret, salt []byte, p KDFParams) ([]byte, error)
+
+var kdfDefaults = map[KDFAlgorithm]KDFParams{
+	// RFC 9106, second recommended option.
+	Argon2id: {Iterations: 3, Memory: 64 * 1024, Parallelism: 4, SaltLength: 16, KeyLength: 32},
+	// OWASP password storage recommendations for the rest.
+	Scrypt:        {Cost: 17, BlockSize: 8, Parallelism: 1, SaltLength: 16, KeyLength: 32},
+	Bcrypt:        {Cost: 12},
+	PBKDF2_SHA256: {Iterations: 600000, SaltLength: 16, KeyLength: 32},
+	PBKDF2_SHA512: {Iterations: 210000, SaltLength: 16, KeyLength: 64},
+	HKDF_SHA256:   {KeyLength: 32},
+	HKDF_SHA512:   {KeyLength: 64},
+}
+
+func init() {
+	registerKDF(Argon2id, func(secret, salt []byte, p KDFParams) ([]byte, error) {
+		if p.Iterations < 1 || p.Parallelism < 1 || p.Parallelism > 255 || p.Memory < 8*p.Parallelism {
+			return nil, ErrInvalidParams
+		}
+		return argon2.IDKey(secret, salt, uint32(p.Iterations), uint32(p.Memory), uint8(p.Parallelism), uint32(p.KeyLength)), nil
+	})
+	registerKDF(Scrypt, func(secret, salt []byte, p KDFParams) ([]byte, error) {
+		if p.Cost < 1 || p.Cost > 62 {
+			return nil, ErrInvalidParams
+		}
+		key, err := scrypt.Key(secret, salt, 1<<p.Cost, p.BlockSize, p.Parallelism, p.KeyLength)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidParams, err)
+		}
+		return key, nil
+	})
+	registerKDF(Bcrypt, func(secret, salt []byte, p KDFParams) ([]byte, error) {
+		// bcrypt generates its own salt and has a fixed output, it cannot derive keys.
+		return nil, ErrUnsupported
+	})
+	registerKDF(PBKDF2_SHA256, pbkdf2Func(sha256.New))
+	registerKDF(PBKDF2_SHA512, pbkdf2Func(sha512.New))
+	registerKDF(HKDF_SHA256, hkdfFunc(sha256.New))
+	registerKDF(HKDF_SHA512, hkdfFunc(sha512.New))
+}
+
+func registerKDF(algo KDFAlgorithm, fn kdfFunc) {
+	register(string(algo), algorithmEntry{class: ClassKDF, kdf: fn})
+}
+
+func pbkdf2Func(h func() hash.Hash) kdfFunc {
+	return func(secret, salt []byte, p KDFParams) ([]byte, error) {
+		if p.Iterations < 1 {
+			return nil, ErrInvalidParams
+		}
+		return pbkdf2.Key(secret, salt, p.Iterations, p.KeyLength, h), nil
+	}
+}
+
+func hkdfFunc(h func() hash.Hash) kdfFunc {
+	return func(secret, salt []byte, p KDFParams) ([]byte, error) {
+		key := make([]byte, p.KeyLength)
+		if _, err := io.ReadFull(hkdf.New(h, secret, salt, p.Info), key); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidParams, err)
+		}
+		return key, nil
+	}
+}
+
+// DefaultKDFParams returns the parameters used when none are given.
+func DefaultKDFParams(algo KDFAlgorithm) KDFParams {
+	return kdfDefaults[normalizeAlgName[KDFAlgorithm](string(algo))]
+}
+
+// withDefaults fills the zero fields of the first params, if any, from the defaults.
+func (k KDFAlgorithm) withDefaults(params []KDFParams) KDFParams {
+	p := DefaultKDFParams(k)
+	if len(params) == 0 {
+		return p
+	}
+
+	given := params[0]
+	for _, f := range []struct{ dst, src *int }{
+		{&p.Iterations, &given.Iterations},
+		{&p.Memory, &given.Memory},
+		{&p.Parallelism, &given.Parallelism},
+		{&p.Cost, &given.Cost},
+		{&p.BlockSize, &given.BlockSize},
+		{&p.SaltLength, &given.SaltLength},
+		{&p.KeyLength, &given.KeyLength},
+	} {
+		if *f.src != 0 {
+			*f.dst = *f.src
+		}
+	}
+	p.Info = given.Info
+	return p
+}
+
+func getKDFFunc(algo KDFAlgorithm) (kdfFunc, error) {
+	entry, ok := lookup(string(algo))
+	if !ok || entry.class != ClassKDF {
+		return nil, ErrUnsupported
+	}
+	return entry.kdf, nil
+}
+
+// DeriveKey derives a key of params.KeyLength bytes from secret and salt.
+// Bcrypt cannot be used to derive keys and returns ErrUnsupported.
+func (k KDFAlgorithm) DeriveKey(secret, salt []byte, params ...KDFParams) ([]byte, error) {
+	fn, err := getKDFFunc(k)
+	if err != nil {
+		return nil, err
+	}
+
+	p := k.withDefaults(params)
+	if p.KeyLength <= 0 && normalizeAlgName[KDFAlgorithm](string(k)) != Bcrypt {
+		return nil, ErrInvalidParams
+	}
+	return fn(secret, salt, p)
+}
+
+// HashPassword hashes password with a random salt and returns it in the PHC string
+// format, for example $argon2id$v=19$m=65536,t=3,p=4$<salt>$<hash>.
+// Bcrypt hashes use the usual $2a$ format. HKDF is not a password hash and
+// returns ErrUnsupported.
+func (k KDFAlgorithm) HashPassword(password []byte, params ...KDFParams) (string, error) {
+	fn, err := getKDFFunc(k)
+	if err != nil {
+		return "", err
+	}
+	algo := normalizeAlgName[KDFAlgorithm](string(k))
+	p := algo.withDefaults(params)
+
+	switch algo {
+	case HKDF_SHA256, HKDF_SHA512:
+		return "", ErrUnsupported
+	case Bcrypt:
+		encoded, err := bcrypt.GenerateFromPassword(password, p.Cost)
+		if err != nil {
+			return "", err
+		}
+		return string(encoded), nil
+	}
+
+	if p.SaltLength <= 0 || p.KeyLength <= 0 {
+		return "", ErrInvalidParams
+	}
+	salt := make([]byte, p.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key, err := fn(password, salt, p)
+	if err != nil {
+		return "", err
+	}
+
+	ph := &PasswordHash{
+		Algorithm: algo,
+		Params:    p,
+		Salt:      salt,
+		Key:       key,
+	}
+	return ph.String(), nil
+}
+
+// PasswordHash is a parsed password hash.
+type PasswordHash struct {
+	Algorithm KDFAlgorithm
+	// Params holds the cost parameters and the salt and key lengths found in the encoding.
+	Params KDFParams
+	Salt   []byte
+	Key    []byte
+
+	// bcrypt keeps the original encoding, which the bcrypt package verifies itself.
+	bcrypt []byte
+}
+
+var phcEncoding = base64.RawStdEncoding
+
+// ParsePasswordHash parses a PHC string produced by HashPassword, or a bcrypt hash.
+// Hashes with a salt shorter than 8 bytes, a key shorter than 16 bytes or cost
+// parameters far beyond any sensible setting are rejected with ErrInvalidPasswordHash.
+func ParsePasswordHash(encoded string) (*PasswordHash, error) {
+	if strings.HasPrefix(encoded, "$2") {
+		cost, err := bcrypt.Cost([]byte(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPasswordHash, err)
+		}
+		return &PasswordHash{
+			Algorithm: Bcrypt,
+			Params:    KDFParams{Cost: cost},
+			bcrypt:    []byte(encoded),
+		}, nil
+	}
+
+	fields := strings.Split(encoded, "$")
+	if len(fields) < 5 || fields[0] != "" {
+		return nil, ErrInvalidPasswordHash
+	}
+
+	algo := KDFAlgorithm(fields[1])
+	if class, ok := ClassOf(algo); !ok || class != ClassKDF {
+		return nil, &UnknownAlgorithmError{Algorithm: fields[1]}
+	}
+
+	fields = fields[2:]
+	if algo == Argon2id {
+		if fields[0] != fmt.Sprintf("v=%d", argon2.Version) {
+			return nil, fmt.Errorf("%w: unsupported argon2 version %s", ErrInvalidPasswordHash, fields[0])
+		}
+		fields = fields[1:]
+	}
+	if len(fields) != 3 {
+		return nil, ErrInvalidPasswordHash
+	}
+
+	values, err := parsePHCParams(fields[0])
+	if err != nil {
+		return nil, err
+	}
+	salt, err := phcEncoding.DecodeString(fields[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPasswordHash, err)
+	}
+	key, err := phcEncoding.DecodeString(fields[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPasswordHash, err)
+	}
+
+	p := KDFParams{SaltLength: len(salt), KeyLength: len(key)}
+	var names []string
+	switch algo {
+	case Argon2id:
+		names = []string{"m", "t", "p"}
+		p.Memory, p.Iterations, p.Parallelism = values["m"], values["t"], values["p"]
+	case Scrypt:
+		names = []string{"ln", "r", "p"}
+		p.Cost, p.BlockSize, p.Parallelism = values["ln"], values["r"], values["p"]
+	case PBKDF2_SHA256, PBKDF2_SHA512:
+		names = []string{"i"}
+		p.Iterations = values["i"]
+	default:
+		return nil, ErrUnsupported
+	}
+	if len(values) != len(names) {
+		return nil, ErrInvalidPasswordHash
+	}
+	for _, name := range names {
+		if values[name] <= 0 {
+			return nil, ErrInvalidPasswordHash
+		}
+	}
+
+	ph := &PasswordHash{
+		Algorithm: algo,
+		Params:    p,
+		Salt:      salt,
+		Key:       key,
+	}
+	if err := ph.validate(); err != nil {
+		return nil, err
+	}
+	return ph, nil
+}
+
+// Limits on parsed password hashes. Stored hashes are not trusted to pick their own
+// cost, so a tampered hash cannot force a derivation that takes gigabytes of memory
+// or minutes of CPU. They sit well above the defaults and the OWASP recommendations.
+const (
+	minPasswordSaltLength = 8
+	maxPasswordSaltLength = 1024
+	minPasswordKeyLength  = 16
+	maxPasswordKeyLength  = 1024
+
+	maxArgon2Memory     = 1 << 20 // KiB, 1 GiB
+	maxArgon2Iterations = 64
+	maxScryptCost       = 24
+	maxScryptBlockSize  = 64
+	maxScryptParallel   = 16
+	maxScryptMemory     = 1 << 30 // bytes, 128 * r * N
+	maxPBKDF2Iterations = 10_000_000
+)
+
+// validate checks that the salt and key are of sensible size and the cost
+// parameters are within the limits above.
+func (ph *PasswordHash) validate() error {
+	if len(ph.Salt) < minPasswordSaltLength || len(ph.Salt) > maxPasswordSaltLength {
+		return fmt.Errorf("%w: salt is %d bytes", ErrInvalidPasswordHash, len(ph.Salt))
+	}
+	if len(ph.Key) < minPasswordKeyLength || len(ph.Key) > maxPasswordKeyLength {
+		return fmt.Errorf("%w: key is %d bytes", ErrInvalidPasswordHash, len(ph.Key))
+	}
+
+	p := ph.Params
+	switch ph.Algorithm {
+	case Argon2id:
+		if p.Memory > maxArgon2Memory || p.Iterations > maxArgon2Iterations || p.Parallelism > 255 {
+			return fmt.Errorf("%w: argon2 parameters m=%d,t=%d,p=%d exceed the limits", ErrInvalidPasswordHash, p.Memory, p.Iterations, p.Parallelism)
+		}
+	case Scrypt:
+		if p.Cost > maxScryptCost || p.BlockSize > maxScryptBlockSize || p.Parallelism > maxScryptParallel ||
+			128*p.BlockSize<<p.Cost > maxScryptMemory {
+			return fmt.Errorf("%w: scrypt parameters ln=%d,r=%d,p=%d exceed the limits", ErrInvalidPasswordHash, p.Cost, p.BlockSize, p.Parallelism)
+		}
+	case PBKDF2_SHA256, PBKDF2_SHA512:
+		if p.Iterations > maxPBKDF2Iterations {
+			return fmt.Errorf("%w: pbkdf2 iterations i=%d exceed the limit", ErrInvalidPasswordHash, p.Iterations)
+		}
+	}
+	return nil
+}
+
+func parsePHCParams(s string) (map[string]int, error) {
+	values := make(map[string]int)
+	for _, pair := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, ErrInvalidPasswordHash
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPasswordHash, err)
+		}
+		values[name] = n
+	}
+	return values, nil
+}
+
+// String returns the PHC encoding, or the bcrypt encoding for bcrypt hashes.
+func (ph *PasswordHash) String() string {
+	if ph.Algorithm == Bcrypt {
+		return string(ph.bcrypt)
+	}
+
+	var params string
+	switch ph.Algorithm {
+	case Argon2id:
+		params = fmt.Sprintf("v=%d$m=%d,t=%d,p=%d", argon2.Version, ph.Params.Memory, ph.Params.Iterations, ph.Params.Parallelism)
+	case Scrypt:
+		params = fmt.Sprintf("ln=%d,r=%d,p=%d", ph.Params.Cost, ph.Params.BlockSize, ph.Params.Parallelism)
+	default:
+		params = fmt.Sprintf("i=%d", ph.Params.Iterations)
+	}
+	return fmt.Sprintf("$%s$%s$%s$%s", ph.Algorithm, params, phcEncoding.EncodeToString(ph.Salt), phcEncoding.EncodeToString(ph.Key))
+}
+
+// Verify re-derives the key from password and returns ErrMismatch if it differs.
+func (ph *PasswordHash) Verify(password []byte) error {
+	if ph.Algorithm == Bcrypt {
+		err := bcrypt.CompareHashAndPassword(ph.bcrypt, password)
+		if err == bcrypt.ErrMismatchedHashAndPassword {
+			return ErrMismatch
+		}
+		return err
+	}
+
+	if err := ph.validate(); err != nil {
+		return err
+	}
+	fn, err := getKDFFunc(ph.Algorithm)
+	if err != nil {
+		return err
+	}
+	key, err := fn(password, ph.Salt, ph.Params)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(key, ph.Key) != 1 {
+		return ErrMismatch
+	}
+	return nil
+}
+
+// NeedsRehash reports whether the hash was produced with another algorithm or other
+// parameters than algo with params, so that it should be replaced on the next login.
+func (ph *PasswordHash) NeedsRehash(algo KDFAlgorithm, params ...KDFParams) bool {
+	algo = normalizeAlgName[KDFAlgorithm](string(algo))
+	if ph.Algorithm != algo {
+		return true
+	}
+
+	want, got := algo.withDefaults(params), ph.Params
+	switch algo {
+	case Bcrypt:
+		return got.Cost != want.Cost
+	case Argon2id:
+		if got.Memory != want.Memory || got.Iterations != want.Iterations || got.Parallelism != want.Parallelism {
+			return true
+		}
+	case Scrypt:
+		if got.Cost != want.Cost || got.BlockSize != want.BlockSize || got.Parallelism != want.Parallelism {
+			return true
+		}
+	default:
+		if got.Iterations != want.Iterations {
+			return true
+		}
+	}
+	return got.SaltLength != want.SaltLength || got.KeyLength != want.KeyLength
+}
+
+// VerifyPassword parses encoded and checks password against it.
+func VerifyPassword(encoded string, password []byte) error {
+	ph, err := ParsePasswordHash(encoded)
+	if err != nil {
+		return err
+	}
+	return ph.Verify(password)
+}
+
+// NeedsRehash parses encoded and reports whether it should be rehashed with algo and params.
+func NeedsRehash(encoded string, algo KDFAlgorithm, params ...KDFParams) (bool, error) {
+	ph, err := ParsePasswordHash(encoded)
+	if err != nil {
+		return false, err
+	}
+	return ph.NeedsRehash(algo, params...), nil
+}
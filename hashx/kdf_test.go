@@ -0,0 +1,199 @@
+package hashx
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// RFC 5869 test case 1, RFC 7914 section 12 and the PBKDF2-HMAC-SHA256 vectors
+// derived from RFC 6070.
+func TestKDFVectors(t *testing.T) {
+	tests := []struct {
+		algo   KDFAlgorithm
+		secret string // hex
+		salt   string // hex
+		params KDFParams
+		key    string
+	}{
+		{
+			algo:   HKDF_SHA256,
+			secret: strings.Repeat("0b", 22),
+			salt:   "000102030405060708090a0b0c",
+			params: KDFParams{KeyLength: 42, Info: []byte{0xf0, 0xf1, 0xf2, 0xf3, 0xf4, 0xf5, 0xf6, 0xf7, 0xf8, 0xf9}},
+			key:    "3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf34007208d5b887185865",
+		},
+		{
+			algo:   Scrypt,
+			secret: hex.EncodeToString([]byte("password")),
+			salt:   hex.EncodeToString([]byte("NaCl")),
+			params: KDFParams{Cost: 10, BlockSize: 8, Parallelism: 16, KeyLength: 64},
+			key:    "fdbabe1c9d3472007856e7190d01e9fe7c6ad7cbc8237830e77376634b3731622eaf30d92e22a3886ff109279d9830dac727afb94a83ee6d8360cbdfa2cc0640",
+		},
+		{
+			algo:   PBKDF2_SHA256,
+			secret: hex.EncodeToString([]byte("password")),
+			salt:   hex.EncodeToString([]byte("salt")),
+			params: KDFParams{Iterations: 4096, KeyLength: 32},
+			key:    "c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.algo), func(t *testing.T) {
+			secret, _ := hex.DecodeString(tt.secret)
+			salt, _ := hex.DecodeString(tt.salt)
+			key, err := tt.algo.DeriveKey(secret, salt, tt.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hex.EncodeToString(key); got != tt.key {
+				t.Fatalf("expected %s, got %s", tt.key, got)
+			}
+		})
+	}
+
+	if _, err := Bcrypt.DeriveKey([]byte("secret"), nil); err != ErrUnsupported {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+}
+
+// Cheap parameters so the test runs quickly; production code uses the defaults.
+var cheapParams = map[KDFAlgorithm]KDFParams{
+	Argon2id:      {Iterations: 1, Memory: 64, Parallelism: 1},
+	Scrypt:        {Cost: 4},
+	Bcrypt:        {Cost: 4},
+	PBKDF2_SHA256: {Iterations: 10},
+	PBKDF2_SHA512: {Iterations: 10},
+}
+
+func TestHashPassword(t *testing.T) {
+	password := []byte("correct horse battery staple")
+
+	for algo, params := range cheapParams {
+		t.Run(string(algo), func(t *testing.T) {
+			encoded, err := algo.HashPassword(password, params)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := VerifyPassword(encoded, password); err != nil {
+				t.Fatal(err)
+			}
+			if err := VerifyPassword(encoded, []byte("Tr0ub4dor&3")); err != ErrMismatch {
+				t.Fatalf("expected ErrMismatch, got %v", err)
+			}
+
+			ph, err := ParsePasswordHash(encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ph.String() != encoded {
+				t.Fatalf("round trip mismatch: %s != %s", ph.String(), encoded)
+			}
+			if ph.NeedsRehash(algo, params) {
+				t.Fatalf("%s should not need a rehash with the parameters it was made with", encoded)
+			}
+			if !ph.NeedsRehash(algo) {
+				t.Fatalf("%s should need a rehash with the default parameters", encoded)
+			}
+			other := Argon2id
+			if algo == Argon2id {
+				other = Scrypt
+			}
+			if !ph.NeedsRehash(other, cheapParams[other]) {
+				t.Fatalf("%s should need a rehash with %s", encoded, other)
+			}
+
+			again, _ := algo.HashPassword(password, params)
+			if again == encoded {
+				t.Fatal("expected a fresh salt for every hash")
+			}
+		})
+	}
+
+	encoded, _ := Argon2id.HashPassword(password, cheapParams[Argon2id])
+	if !strings.HasPrefix(encoded, "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Fatalf("unexpected PHC string %s", encoded)
+	}
+	if _, err := HKDF_SHA256.HashPassword(password); err != ErrUnsupported {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+}
+
+func TestParsePasswordHash(t *testing.T) {
+	// From the Argon2 reference implementation's test suite.
+	const reference = "$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc"
+	ph, err := ParsePasswordHash(reference)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ph.Params.Memory != 65536 || ph.Params.Iterations != 2 || ph.Params.Parallelism != 1 || string(ph.Salt) != "somesalt" {
+		t.Fatalf("unexpected parameters %+v", ph.Params)
+	}
+	if err := ph.Verify([]byte("password")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, encoded := range []string{
+		"",
+		"argon2id",
+		"$argon2id$v=16$m=64,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=64,t=1$c2FsdA$a2V5",
+		"$scrypt$ln=x,r=8,p=1$c2FsdA$a2V5",
+		"$pbkdf2-sha256$i=0$c2FsdA$a2V5",
+		"$pbkdf2-sha256$i=10$c2FsdA",
+		"$pbkdf2-sha256$i=10$!!$a2V5",
+		"$2a$99$invalid",
+	} {
+		if _, err := ParsePasswordHash(encoded); !errors.Is(err, ErrInvalidPasswordHash) {
+			t.Fatalf("%q: expected ErrInvalidPasswordHash, got %v", encoded, err)
+		}
+	}
+
+	var unknown *UnknownAlgorithmError
+	if _, err := ParsePasswordHash("$sha256$i=1$c2FsdA$a2V5"); !errors.As(err, &unknown) {
+		t.Fatalf("expected UnknownAlgorithmError, got %v", err)
+	}
+}
+
+// An empty key used to verify every password, and panicked in argon2. Stored hashes
+// must also not be able to pick an unbounded cost.
+func TestParsePasswordHashLimits(t *testing.T) {
+	const (
+		salt = "c2FsdHNhbHQ"            // "saltsalt"
+		key  = "MDEyMzQ1Njc4OWFiY2RlZg" // 16 bytes
+	)
+	for _, encoded := range []string{
+		"$pbkdf2-sha256$i=1$" + salt + "$",
+		"$scrypt$ln=4,r=8,p=1$" + salt + "$",
+		"$argon2id$v=19$m=64,t=1,p=1$" + salt + "$",
+		"$pbkdf2-sha256$i=1$" + salt + "$a2V5",
+		"$pbkdf2-sha256$i=1$$" + key,
+		"$argon2id$v=19$m=64,t=1,p=1$$" + key,
+		"$argon2id$v=19$m=4194304,t=1,p=1$" + salt + "$" + key,
+		"$argon2id$v=19$m=64,t=1000000,p=1$" + salt + "$" + key,
+		"$scrypt$ln=40,r=8,p=1$" + salt + "$" + key,
+		"$scrypt$ln=20,r=64,p=1$" + salt + "$" + key,
+		"$scrypt$ln=4,r=8,p=1000$" + salt + "$" + key,
+		"$pbkdf2-sha512$i=2000000000$" + salt + "$" + key,
+	} {
+		if _, err := ParsePasswordHash(encoded); !errors.Is(err, ErrInvalidPasswordHash) {
+			t.Errorf("%q: expected ErrInvalidPasswordHash, got %v", encoded, err)
+		}
+		if err := VerifyPassword(encoded, []byte("anything")); err == nil {
+			t.Errorf("%q: accepted any password", encoded)
+		}
+	}
+
+	// A hand-built hash is checked by Verify as well.
+	ph := &PasswordHash{Algorithm: PBKDF2_SHA256, Params: KDFParams{Iterations: 1}, Salt: []byte("saltsalt")}
+	if err := ph.Verify([]byte("anything")); !errors.Is(err, ErrInvalidPasswordHash) {
+		t.Fatalf("expected ErrInvalidPasswordHash, got %v", err)
+	}
+
+	if _, err := ParsePasswordHash("$pbkdf2-sha256$i=1$" + salt + "$" + key); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,344 @@
+package manifest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/atlastore/belt/hashx"
+)
+
+// Format is the layout of a written manifest.
+type Format int
+
+const (
+	// FormatBSD is the tagged format of BSD sha256 and sha256sum --tag, one line
+	// per file and algorithm: "SHA256 (path) = hex".
+	FormatBSD Format = iota + 1
+	// FormatGNU is the default format of sha256sum, "hex  path".
+	// It holds a single algorithm.
+	FormatGNU
+	// FormatJSON also records file sizes.
+	FormatJSON
+)
+
+var ErrSingleAlgorithm = errors.New("manifest: format holds a single algorithm")
+var ErrUnknownFormat = errors.New("manifest: unknown format")
+
+// jsonVersion is written to JSON manifests so the layout can change later.
+const jsonVersion = 1
+
+// gnuAlgorithms guesses the algorithm of a GNU format manifest from the digest
+// length, as shasum -c does.
+var gnuAlgorithms = map[int]string{
+	16: string(hashx.MD5),
+	20: string(hashx.SHA1),
+	28: string(hashx.SHA224),
+	32: string(hashx.SHA256),
+	48: string(hashx.SHA384),
+	64: string(hashx.SHA512),
+}
+
+// Write writes the manifest in the given format. sha256sum -c and its siblings
+// accept the BSD and GNU formats, including for names with newlines or
+// backslashes, which are escaped the same way coreutils does.
+func (m *Manifest) Write(w io.Writer, format Format) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(m)
+	case FormatBSD, FormatGNU:
+	default:
+		return ErrUnknownFormat
+	}
+	if format == FormatGNU && len(m.Algorithms) != 1 {
+		return ErrSingleAlgorithm
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, e := range m.Entries {
+		path, escaped := escapePath(e.Path)
+		for _, sum := range e.Sums {
+			if escaped {
+				bw.WriteByte('\\')
+			}
+			digest := hex.EncodeToString(sum.Bytes())
+			if format == FormatBSD {
+				fmt.Fprintf(bw, "%s (%s) = %s\n", strings.ToUpper(sum.Algorithm()), path, digest)
+			} else {
+				fmt.Fprintf(bw, "%s  %s\n", digest, path)
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// Read parses a manifest in any of the formats, telling them apart by content.
+// The algorithm of a GNU format manifest is guessed from the digest length among
+// MD5 and the SHA-1 and SHA-2 family; use ReadGNU for any other algorithm.
+// Entries read from BSD or GNU manifests have UnknownSize.
+func Read(r io.Reader) (*Manifest, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		m := new(Manifest)
+		if err := json.Unmarshal(trimmed, m); err != nil {
+			return nil, err
+		}
+		return m, nil
+	}
+	return readText(data, "")
+}
+
+// ReadGNU parses a GNU format manifest whose digests were computed with algo.
+func ReadGNU(r io.Reader, algo string) (*Manifest, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return readText(data, algo)
+}
+
+func readText(data []byte, gnuAlgo string) (*Manifest, error) {
+	m := new(Manifest)
+	entries := make(map[string]*Entry)
+
+	for n, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		algo, path, digest, err := parseLine(line, gnuAlgo)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidManifest, n+1, err)
+		}
+		sum, err := hashx.NewHashSum(algo, digest)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidManifest, n+1, err)
+		}
+
+		e, ok := entries[path]
+		if !ok {
+			e = &Entry{Path: path, Size: UnknownSize}
+			entries[path] = e
+		}
+		if e.Sum(algo) != nil {
+			return nil, fmt.Errorf("%w: line %d: duplicate %s sum for %q", ErrInvalidManifest, n+1, algo, path)
+		}
+		e.Sums = append(e.Sums, sum)
+		if !slices.Contains(m.Algorithms, algo) {
+			m.Algorithms = append(m.Algorithms, algo)
+		}
+	}
+
+	for _, e := range entries {
+		m.Entries = append(m.Entries, *e)
+	}
+	if err := m.normalize(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// parseLine splits a BSD "TAG (path) = hex" or GNU "hex  path" line.
+// Without gnuAlgo, GNU lines are recognised by their leading hex digest.
+func parseLine(line, gnuAlgo string) (algo, path string, digest []byte, err error) {
+	escaped := strings.HasPrefix(line, "\\")
+	if escaped {
+		line = line[1:]
+	}
+
+	if first, rest, ok := strings.Cut(line, " "); ok && isHex(first) && (strings.HasPrefix(rest, " ") || strings.HasPrefix(rest, "*")) {
+		digest, _ = hex.DecodeString(first)
+		algo = canonical(gnuAlgo)
+		if algo == "" {
+			if algo, ok = gnuAlgorithms[len(digest)]; !ok {
+				return "", "", nil, fmt.Errorf("cannot tell the algorithm of a %d byte digest", len(digest))
+			}
+		}
+		path = rest[1:]
+	} else {
+		tag, rest, ok := strings.Cut(line, " (")
+		i := strings.LastIndex(rest, ") = ")
+		if !ok || i < 0 || !isHex(rest[i+4:]) {
+			return "", "", nil, errors.New("malformed line")
+		}
+		algo = canonical(tag)
+		path = rest[:i]
+		digest, _ = hex.DecodeString(rest[i+4:])
+	}
+
+	if escaped {
+		if path, err = unescapePath(path); err != nil {
+			return "", "", nil, err
+		}
+	}
+	return algo, path, digest, nil
+}
+
+func isHex(s string) bool {
+	if len(s) == 0 || len(s)%2 != 0 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// escapePath escapes backslashes, newlines and carriage returns as coreutils does,
+// reporting whether the line needs the leading backslash that marks it escaped.
+func escapePath(path string) (string, bool) {
+	if !strings.ContainsAny(path, "\\\n\r") {
+		return path, false
+	}
+	r := strings.NewReplacer("\\", "\\\\", "\n", "\\n", "\r", "\\r")
+	return r.Replace(path), true
+}
+
+func unescapePath(path string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] != '\\' {
+			b.WriteByte(path[i])
+			continue
+		}
+		i++
+		if i == len(path) {
+			return "", errors.New("trailing backslash in path")
+		}
+		switch path[i] {
+		case '\\':
+			b.WriteByte('\\')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		default:
+			return "", fmt.Errorf("unknown escape \\%c in path", path[i])
+		}
+	}
+	return b.String(), nil
+}
+
+type jsonManifest struct {
+	Version    int         `json:"version"`
+	Algorithms []string    `json:"algorithms"`
+	Files      []jsonEntry `json:"files"`
+}
+
+type jsonEntry struct {
+	Path string            `json:"path"`
+	Size *int64            `json:"size,omitempty"`
+	Sums map[string]string `json:"sums"`
+}
+
+func (m Manifest) MarshalJSON() ([]byte, error) {
+	jm := jsonManifest{
+		Version:    jsonVersion,
+		Algorithms: m.Algorithms,
+		Files:      make([]jsonEntry, len(m.Entries)),
+	}
+	for i, e := range m.Entries {
+		je := jsonEntry{
+			Path: e.Path,
+			Sums: make(map[string]string, len(e.Sums)),
+		}
+		if e.Size != UnknownSize {
+			je.Size = &e.Size
+		}
+		for _, sum := range e.Sums {
+			je.Sums[sum.Algorithm()] = hex.EncodeToString(sum.Bytes())
+		}
+		jm.Files[i] = je
+	}
+	return json.Marshal(jm)
+}
+
+func (m *Manifest) UnmarshalJSON(data []byte) error {
+	var jm jsonManifest
+	if err := json.Unmarshal(data, &jm); err != nil {
+		return err
+	}
+	if jm.Version != jsonVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidManifest, jm.Version)
+	}
+
+	out := Manifest{
+		Algorithms: make([]string, len(jm.Algorithms)),
+		Entries:    make([]Entry, len(jm.Files)),
+	}
+	for i, algo := range jm.Algorithms {
+		out.Algorithms[i] = canonical(algo)
+	}
+	for i, je := range jm.Files {
+		e := Entry{Path: je.Path, Size: UnknownSize}
+		if je.Size != nil {
+			e.Size = *je.Size
+		}
+		for algo, digest := range je.Sums {
+			raw, err := hex.DecodeString(digest)
+			if err != nil {
+				return fmt.Errorf("%w: %q: %v", ErrInvalidManifest, je.Path, err)
+			}
+			sum, err := hashx.NewHashSum(canonical(algo), raw)
+			if err != nil {
+				return fmt.Errorf("%w: %q: %v", ErrInvalidManifest, je.Path, err)
+			}
+			e.Sums = append(e.Sums, sum)
+		}
+		out.Entries[i] = e
+	}
+
+	if err := out.normalize(); err != nil {
+		return err
+	}
+	*m = out
+	return nil
+}
+
+// normalize sorts the entries and their sums and checks that every entry has a
+// valid, unique path and exactly one sum per algorithm.
+func (m *Manifest) normalize() error {
+	sort.Slice(m.Entries, func(i, j int) bool {
+		return m.Entries[i].Path < m.Entries[j].Path
+	})
+
+	for i := range m.Entries {
+		e := &m.Entries[i]
+		if !fs.ValidPath(e.Path) || e.Path == "." {
+			return fmt.Errorf("%w: invalid path %q", ErrInvalidManifest, e.Path)
+		}
+		if i > 0 && m.Entries[i-1].Path == e.Path {
+			return fmt.Errorf("%w: duplicate path %q", ErrInvalidManifest, e.Path)
+		}
+		if len(e.Sums) != len(m.Algorithms) {
+			return fmt.Errorf("%w: %q does not have a sum for every algorithm", ErrInvalidManifest, e.Path)
+		}
+
+		sums := make([]*hashx.HashSum, len(m.Algorithms))
+		for j, algo := range m.Algorithms {
+			if sums[j] = e.Sum(algo); sums[j] == nil {
+				return fmt.Errorf("%w: %q has no %s sum", ErrInvalidManifest, e.Path, algo)
+			}
+		}
+		e.Sums = sums
+	}
+	return nil
+}
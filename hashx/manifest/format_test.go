@@ -0,0 +1,139 @@
+package manifest
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/atlastore/belt/hashx"
+	"github.com/atlastore/belt/hashx/multi"
+)
+
+func TestWriteText(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.txt":       {Data: []byte("abc")},
+		"dir/hello":   {Data: []byte("hello\n")},
+		"odd\\name\n": {Data: []byte("abc")},
+	}
+	m, err := Build(fsys, Options{}, multi.Unkeyed(hashx.SHA256))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var gnu bytes.Buffer
+	if err := m.Write(&gnu, FormatGNU); err != nil {
+		t.Fatal(err)
+	}
+	// sha256sum a.txt dir/hello 'odd\name<newline>'
+	want := "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad  a.txt\n" +
+		"5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03  dir/hello\n" +
+		"\\ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad  odd\\\\name\\n\n"
+	if gnu.String() != want {
+		t.Fatalf("GNU format:\n%s\nexpected:\n%s", gnu.String(), want)
+	}
+
+	var bsd bytes.Buffer
+	if err := m.Write(&bsd, FormatBSD); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(bsd.String(), "SHA256 (a.txt) = ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad\n") {
+		t.Fatalf("BSD format:\n%s", bsd.String())
+	}
+
+	for _, out := range []*bytes.Buffer{&gnu, &bsd} {
+		read, err := Read(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r := Compare(m, read); !r.OK() || r.Checked != 3 {
+			t.Fatalf("round trip differs:\n%s", r)
+		}
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	m, err := Build(testTree(), Options{}, multi.Unkeyed(hashx.SHA256), multi.Unkeyed(hashx.FNV64))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := m.Write(&buf, FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Write(&bytes.Buffer{}, FormatGNU); err != ErrSingleAlgorithm {
+		t.Fatalf("expected ErrSingleAlgorithm, got %v", err)
+	}
+
+	read, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read.Algorithms) != 2 || read.Algorithms[1] != "fnv64" {
+		t.Fatalf("algorithms %q", read.Algorithms)
+	}
+	if r := Compare(m, read); !r.OK() || r.Checked != len(m.Entries) {
+		t.Fatalf("round trip differs:\n%s", r)
+	}
+	e, _ := read.Lookup("hello.txt")
+	if e.Size != 6 {
+		t.Fatalf("size %d, expected 6", e.Size)
+	}
+}
+
+func TestReadMultipleAlgorithms(t *testing.T) {
+	in := "# comment\n" +
+		"SHA256 (x) = ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad\n" +
+		"MD5 (x) = 900150983cd24fb0d6963f7d28e17f72\r\n" +
+		"MD5 (a (1).txt) = 900150983cd24fb0d6963f7d28e17f72\n" +
+		"SHA256 (a (1).txt) = ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad\n"
+	m, err := Read(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Entries) != 2 || m.Entries[0].Path != "a (1).txt" {
+		t.Fatalf("entries %+v", m.Entries)
+	}
+	if m.Algorithms[0] != "sha256" || m.Entries[0].Sums[0].Algorithm() != "sha256" {
+		t.Fatal("sums are not in algorithm order")
+	}
+	if m.Entries[0].Size != UnknownSize {
+		t.Fatal("text formats have no size")
+	}
+}
+
+func TestReadGNU(t *testing.T) {
+	// b2sum output cannot be told apart from sha512sum by length.
+	in := "ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d17d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923 *x\n"
+	m, err := Read(strings.NewReader(in))
+	if err != nil || m.Algorithms[0] != "sha512" {
+		t.Fatalf("guessed %v, %v", m, err)
+	}
+	m, err = ReadGNU(strings.NewReader(in), "BLAKE2b")
+	if err != nil || m.Algorithms[0] != "blake2b" {
+		t.Fatalf("got %v, %v", m, err)
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	for name, in := range map[string]string{
+		"garbage":   "not a manifest\n",
+		"bad hex":   "SHA256 (x) = zz\n",
+		"length":    "SHA256 (x) = abcd\n",
+		"unknown":   "NOPE (x) = abcd\n",
+		"guess":     "abcd  x\n",
+		"duplicate": "MD5 (x) = 900150983cd24fb0d6963f7d28e17f72\nMD5 (x) = 900150983cd24fb0d6963f7d28e17f72\n",
+		"partial":   "MD5 (x) = 900150983cd24fb0d6963f7d28e17f72\nSHA1 (y) = a9993e364706816aba3e25717850c26c9cd0d89d\n",
+		"escape":    "\\MD5 (x\\q) = 900150983cd24fb0d6963f7d28e17f72\n",
+		"path":      "MD5 (../x) = 900150983cd24fb0d6963f7d28e17f72\n",
+		"absolute":  "900150983cd24fb0d6963f7d28e17f72  /etc/passwd\n",
+		"version":   `{"version": 2, "algorithms": [], "files": []}`,
+		"json sums": `{"version": 1, "algorithms": ["md5"], "files": [{"path": "x", "sums": {}}]}`,
+	} {
+		if _, err := Read(strings.NewReader(in)); !errors.Is(err, ErrInvalidManifest) {
+			t.Errorf("%s: expected ErrInvalidManifest, got %v", name, err)
+		}
+	}
+}
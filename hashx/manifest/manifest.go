@@ -0,0 +1,259 @@
+// Package manifest records the digests of every file in a directory tree, writes
+// and reads them as sha256sum style or JSON manifests, verifies a tree against a
+// manifest and computes a single deterministic digest for a whole directory.
+//
+// Trees are read through fs.FS, so os.DirFS(root) hashes a directory on disk.
+// Paths are slash separated and relative to the root. Only regular files are
+// recorded: symbolic links, devices and empty directories are not.
+package manifest
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/fs"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/atlastore/belt/hashx"
+	"github.com/atlastore/belt/hashx/multi"
+)
+
+var ErrNoAlgorithms = errors.New("manifest: at least one algorithm is required")
+var ErrInvalidManifest = errors.New("manifest: invalid manifest")
+var ErrNotInManifest = errors.New("manifest: algorithm is not in the manifest")
+
+// UnknownSize is the Size of entries read from formats that do not record it.
+const UnknownSize = -1
+
+// Entry is a single file of a manifest.
+type Entry struct {
+	Path string
+	// Size in bytes, or UnknownSize.
+	Size int64
+	// Sums holds one digest per algorithm of the manifest, in the same order.
+	Sums []*hashx.HashSum
+}
+
+// Sum returns the digest computed with algo, or nil.
+func (e *Entry) Sum(algo string) *hashx.HashSum {
+	algo = canonical(algo)
+	for _, s := range e.Sums {
+		if canonical(s.Algorithm()) == algo {
+			return s
+		}
+	}
+	return nil
+}
+
+// Manifest lists the files of a tree sorted by path.
+type Manifest struct {
+	Algorithms []string
+	Entries    []Entry
+}
+
+// Lookup returns the entry for path.
+func (m *Manifest) Lookup(path string) (*Entry, bool) {
+	i := sort.Search(len(m.Entries), func(i int) bool {
+		return m.Entries[i].Path >= path
+	})
+	if i < len(m.Entries) && m.Entries[i].Path == path {
+		return &m.Entries[i], true
+	}
+	return nil, false
+}
+
+// Options control how a tree is walked and hashed.
+type Options struct {
+	// Workers is the number of files hashed at once, runtime.GOMAXPROCS(0) if zero.
+	Workers int
+	// Skip, when set, is called for every file and directory below the root.
+	// Returning true leaves the file out, or the whole directory.
+	Skip func(path string, d fs.DirEntry) bool
+}
+
+// Build hashes every regular file of fsys with each spec.
+func Build(fsys fs.FS, opts Options, specs ...multi.Spec) (*Manifest, error) {
+	if len(specs) == 0 {
+		return nil, ErrNoAlgorithms
+	}
+	// Check the algorithms and keys before reading anything.
+	if _, err := multi.NewHashersWithKeys(specs...); err != nil {
+		return nil, err
+	}
+
+	paths, err := walk(fsys, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	specs = append([]multi.Spec(nil), specs...)
+	m := &Manifest{
+		Algorithms: make([]string, len(specs)),
+		Entries:    make([]Entry, len(paths)),
+	}
+	for i, spec := range specs {
+		m.Algorithms[i] = canonical(spec.Algorithm)
+		specs[i].Algorithm = m.Algorithms[i]
+	}
+	for i, path := range paths {
+		m.Entries[i].Path = path
+	}
+
+	if err := hashEntries(fsys, opts, m.Entries, specs); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Digest computes a digest of the whole tree from the manifest: the file digests
+// of spec's algorithm are hashed again with it, together with their paths, in path
+// order. It depends only on the paths and contents of the files, not on
+// timestamps, permissions or the order the tree was walked in.
+func (m *Manifest) Digest(spec multi.Spec) (*hashx.HashSum, error) {
+	var key [][]byte
+	if spec.Key != nil {
+		key = append(key, spec.Key)
+	}
+	fn, err := hashx.GetHash(spec.Algorithm, key...)
+	if err != nil {
+		return nil, err
+	}
+
+	h := fn()
+	var buf []byte
+	for i := range m.Entries {
+		e := &m.Entries[i]
+		sum := e.Sum(spec.Algorithm)
+		if sum == nil {
+			return nil, ErrNotInManifest
+		}
+		digest := sum.Bytes()
+
+		buf = binary.AppendUvarint(buf[:0], uint64(len(e.Path)))
+		buf = append(buf, e.Path...)
+		buf = binary.AppendUvarint(buf, uint64(len(digest)))
+		buf = append(buf, digest...)
+		h.Write(buf)
+	}
+
+	return hashx.NewHashSum(canonical(spec.Algorithm), h.Sum(nil))
+}
+
+// DigestDir builds a manifest of fsys with a single algorithm and returns its Digest.
+func DigestDir(fsys fs.FS, opts Options, spec multi.Spec) (*hashx.HashSum, error) {
+	m, err := Build(fsys, opts, spec)
+	if err != nil {
+		return nil, err
+	}
+	return m.Digest(spec)
+}
+
+// walk lists the regular files of fsys in sorted order.
+func walk(fsys fs.FS, opts Options) ([]string, error) {
+	var paths []string
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == "." {
+			return nil
+		}
+		if opts.Skip != nil && opts.Skip(path, d) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.Type().IsRegular() {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// WalkDir visits "a/b" before "a.txt"; manifests are sorted by the full path.
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// hashEntries fills in the size and sums of entries using opts.Workers goroutines.
+func hashEntries(fsys fs.FS, opts Options, entries []Entry, specs []multi.Spec) error {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, len(entries))
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		next     = make(chan int)
+	)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				if err := hashEntry(fsys, &entries[i], specs); err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for i := range entries {
+		mu.Lock()
+		failed := firstErr != nil
+		mu.Unlock()
+		if failed {
+			break
+		}
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return firstErr
+}
+
+func hashEntry(fsys fs.FS, e *Entry, specs []multi.Spec) error {
+	f, err := fsys.Open(e.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	cr := &countingReader{r: f}
+	sums, err := multi.HashReaderSums(cr, specs...)
+	if err != nil {
+		return &fs.PathError{Op: "hash", Path: e.Path, Err: err}
+	}
+	e.Size = cr.n
+	e.Sums = sums
+	return nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
+
+// canonical spells algorithm names the way hashx registers them.
+func canonical(algo string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(algo), "_", "-"))
+}
@@ -0,0 +1,151 @@
+package manifest
+
+import (
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/atlastore/belt/hashx"
+	"github.com/atlastore/belt/hashx/multi"
+)
+
+func testTree() fstest.MapFS {
+	return fstest.MapFS{
+		"hello.txt":       {Data: []byte("hello\n")},
+		"a.txt":           {Data: []byte("abc")},
+		"a/b/c.bin":       {Data: []byte{0, 1, 2, 3}},
+		"a/empty":         {Data: nil},
+		".git/HEAD":       {Data: []byte("ref: refs/heads/main\n")},
+		"link":            {Data: []byte("a.txt"), Mode: fs.ModeSymlink},
+		"dir-only/nested": {Mode: fs.ModeDir},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	m, err := Build(testTree(), Options{}, multi.Unkeyed(hashx.SHA256), multi.Unkeyed(hashx.XXHash))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+	for _, e := range m.Entries {
+		paths = append(paths, e.Path)
+	}
+	want := []string{".git/HEAD", "a.txt", "a/b/c.bin", "a/empty", "hello.txt"}
+	if len(paths) != len(want) {
+		t.Fatalf("paths %q, expected %q", paths, want)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Fatalf("paths %q, expected %q", paths, want)
+		}
+	}
+
+	e, ok := m.Lookup("hello.txt")
+	if !ok {
+		t.Fatal("hello.txt not found")
+	}
+	if e.Size != 6 {
+		t.Fatalf("size %d, expected 6", e.Size)
+	}
+	if got := hex.EncodeToString(e.Sum("SHA256").Bytes()); got != "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03" {
+		t.Fatalf("sha256 %s", got)
+	}
+	if e.Sum("xxhash") == nil || e.Sum("md5") != nil {
+		t.Fatal("unexpected sums")
+	}
+	if _, ok := m.Lookup("link"); ok {
+		t.Fatal("symbolic links must not be recorded")
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	if _, err := Build(testTree(), Options{}); err != ErrNoAlgorithms {
+		t.Fatalf("expected ErrNoAlgorithms, got %v", err)
+	}
+	// Unknown algorithms are rejected even for an empty tree.
+	if _, err := Build(fstest.MapFS{}, Options{}, multi.Unkeyed("nope")); !errors.Is(err, hashx.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+	if _, err := Build(testTree(), Options{}, multi.Unkeyed(hashx.HMAC_SHA256)); err != hashx.ErrKeyRequired {
+		t.Fatalf("expected ErrKeyRequired, got %v", err)
+	}
+}
+
+func TestBuildSkip(t *testing.T) {
+	opts := Options{Skip: func(path string, d fs.DirEntry) bool {
+		return d.IsDir() && d.Name() == ".git"
+	}}
+	m, err := Build(testTree(), opts, multi.Unkeyed(hashx.SHA256))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.Lookup(".git/HEAD"); ok || len(m.Entries) != 4 {
+		t.Fatalf("skipped directory was hashed: %d entries", len(m.Entries))
+	}
+}
+
+func TestDigest(t *testing.T) {
+	spec := multi.Unkeyed(hashx.SHA256)
+
+	d1, err := DigestDir(testTree(), Options{}, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d2, err := DigestDir(testTree(), Options{Workers: 1}, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d1.Encode() != d2.Encode() {
+		t.Fatal("directory digest is not deterministic")
+	}
+
+	for name, change := range map[string]func(fstest.MapFS){
+		"content": func(fsys fstest.MapFS) { fsys["a.txt"] = &fstest.MapFile{Data: []byte("abd")} },
+		"rename":  func(fsys fstest.MapFS) { fsys["a.txt2"] = fsys["a.txt"]; delete(fsys, "a.txt") },
+		"add":     func(fsys fstest.MapFS) { fsys["new"] = &fstest.MapFile{} },
+		// Moving the boundary between path and content must change the digest too.
+		"boundary": func(fsys fstest.MapFS) {
+			delete(fsys, "a/empty")
+			fsys["a/emptyx"] = &fstest.MapFile{}
+		},
+	} {
+		fsys := testTree()
+		change(fsys)
+		d, err := DigestDir(fsys, Options{}, spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.Encode() == d1.Encode() {
+			t.Fatalf("%s: digest did not change", name)
+		}
+	}
+
+	m, _ := Build(testTree(), Options{}, spec)
+	if _, err := m.Digest(multi.Unkeyed(hashx.SHA512)); err != ErrNotInManifest {
+		t.Fatalf("expected ErrNotInManifest, got %v", err)
+	}
+}
+
+func TestBuildDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sub", "f"), []byte("abc"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := Build(os.DirFS(dir), Options{}, multi.Unkeyed(hashx.SHA256))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, ok := m.Lookup("sub/f")
+	if !ok || hex.EncodeToString(e.Sums[0].Bytes()) != "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad" {
+		t.Fatalf("unexpected manifest %+v", m.Entries)
+	}
+}
@@ -0,0 +1,182 @@
+package manifest
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/atlastore/belt/hashx/multi"
+)
+
+// Status is how a file differs from the manifest.
+type Status int
+
+const (
+	// Added files are in the tree but not in the manifest.
+	Added Status = iota + 1
+	// Missing files are in the manifest but not in the tree.
+	Missing
+	// Modified files have a different size or digest.
+	Modified
+)
+
+func (s Status) String() string {
+	switch s {
+	case Added:
+		return "added"
+	case Missing:
+		return "missing"
+	case Modified:
+		return "modified"
+	default:
+		return "unknown"
+	}
+}
+
+// Difference describes one file that does not match. Expected is nil for added
+// files and Actual for missing ones.
+type Difference struct {
+	Path     string
+	Status   Status
+	Expected *Entry
+	Actual   *Entry
+}
+
+// Report is the outcome of Verify or Compare.
+type Report struct {
+	// Checked is the number of files found in both and compared.
+	Checked int
+	// Differences is sorted by path.
+	Differences []Difference
+}
+
+// OK reports whether the tree matched the manifest exactly.
+func (r *Report) OK() bool {
+	return len(r.Differences) == 0
+}
+
+// Paths returns the paths with the given status.
+func (r *Report) Paths(status Status) []string {
+	var paths []string
+	for _, d := range r.Differences {
+		if d.Status == status {
+			paths = append(paths, d.Path)
+		}
+	}
+	return paths
+}
+
+// String lists the differences one per line, as "modified: path".
+func (r *Report) String() string {
+	var b strings.Builder
+	for _, d := range r.Differences {
+		fmt.Fprintf(&b, "%s: %s\n", d.Status, d.Path)
+	}
+	return b.String()
+}
+
+// Verify hashes the files of fsys that are listed in m and reports the files that
+// were added, are missing or were modified. Added files are not read.
+//
+// specs chooses the algorithms to check and supplies the keys of keyed ones; each
+// must be in the manifest. Without specs every algorithm of the manifest is checked,
+// which only works when none of them is keyed.
+func Verify(fsys fs.FS, m *Manifest, opts Options, specs ...multi.Spec) (*Report, error) {
+	if len(specs) == 0 {
+		for _, algo := range m.Algorithms {
+			specs = append(specs, multi.Unkeyed(algo))
+		}
+	} else {
+		specs = append([]multi.Spec(nil), specs...)
+	}
+	if len(specs) == 0 {
+		return nil, ErrNoAlgorithms
+	}
+	for i := range specs {
+		specs[i].Algorithm = canonical(specs[i].Algorithm)
+		if !containsAlgorithm(m.Algorithms, specs[i].Algorithm) {
+			return nil, fmt.Errorf("%w: %s", ErrNotInManifest, specs[i].Algorithm)
+		}
+	}
+	if _, err := multi.NewHashersWithKeys(specs...); err != nil {
+		return nil, err
+	}
+
+	paths, err := walk(fsys, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	actual := &Manifest{Entries: make([]Entry, len(paths))}
+	var listed []Entry
+	var listedIdx []int
+	for i, path := range paths {
+		actual.Entries[i] = Entry{Path: path, Size: UnknownSize}
+		if _, ok := m.Lookup(path); ok {
+			listed = append(listed, Entry{Path: path})
+			listedIdx = append(listedIdx, i)
+		}
+	}
+
+	if err := hashEntries(fsys, opts, listed, specs); err != nil {
+		return nil, err
+	}
+	for j, i := range listedIdx {
+		actual.Entries[i] = listed[j]
+	}
+
+	return Compare(m, actual), nil
+}
+
+// Compare reports how actual differs from expected, for example two manifests of
+// the same tree taken at different times. Files are modified if their sizes are
+// both known and differ, or if any algorithm present in both entries gives a
+// different digest.
+func Compare(expected, actual *Manifest) *Report {
+	r := new(Report)
+	i, j := 0, 0
+	for i < len(expected.Entries) || j < len(actual.Entries) {
+		switch {
+		case j == len(actual.Entries) || i < len(expected.Entries) && expected.Entries[i].Path < actual.Entries[j].Path:
+			e := &expected.Entries[i]
+			r.Differences = append(r.Differences, Difference{Path: e.Path, Status: Missing, Expected: e})
+			i++
+		case i == len(expected.Entries) || actual.Entries[j].Path < expected.Entries[i].Path:
+			a := &actual.Entries[j]
+			r.Differences = append(r.Differences, Difference{Path: a.Path, Status: Added, Actual: a})
+			j++
+		default:
+			e, a := &expected.Entries[i], &actual.Entries[j]
+			r.Checked++
+			if modified(e, a) {
+				r.Differences = append(r.Differences, Difference{Path: e.Path, Status: Modified, Expected: e, Actual: a})
+			}
+			i++
+			j++
+		}
+	}
+	return r
+}
+
+func modified(e, a *Entry) bool {
+	if e.Size != UnknownSize && a.Size != UnknownSize && e.Size != a.Size {
+		return true
+	}
+	for _, want := range e.Sums {
+		got := a.Sum(want.Algorithm())
+		if got != nil && !bytes.Equal(got.Bytes(), want.Bytes()) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAlgorithm(algos []string, algo string) bool {
+	for _, a := range algos {
+		if canonical(a) == algo {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package manifest
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/atlastore/belt/hashx"
+	"github.com/atlastore/belt/hashx/multi"
+)
+
+func TestVerify(t *testing.T) {
+	m, err := Build(testTree(), Options{}, multi.Unkeyed(hashx.SHA256), multi.Unkeyed(hashx.CRC32))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := Verify(testTree(), m, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.OK() || r.Checked != len(m.Entries) {
+		t.Fatalf("unchanged tree reported:\n%s", r)
+	}
+
+	fsys := testTree()
+	fsys["a.txt"] = &fstest.MapFile{Data: []byte("abd")}
+	fsys["a/empty"] = &fstest.MapFile{Data: []byte("x")}
+	fsys["new.txt"] = &fstest.MapFile{Data: []byte("new")}
+	delete(fsys, "hello.txt")
+
+	r, err = Verify(fsys, m, Options{Workers: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "modified: a.txt\nmodified: a/empty\nmissing: hello.txt\nadded: new.txt\n"
+	if r.String() != want {
+		t.Fatalf("report:\n%s\nexpected:\n%s", r, want)
+	}
+	if r.Checked != 4 {
+		t.Fatalf("checked %d, expected 4", r.Checked)
+	}
+	if added := r.Paths(Added); len(added) != 1 || added[0] != "new.txt" {
+		t.Fatalf("added %q", added)
+	}
+	for _, d := range r.Differences {
+		if d.Status == Modified && (d.Expected == nil || d.Actual == nil || d.Actual.Size == UnknownSize) {
+			t.Fatalf("incomplete difference %+v", d)
+		}
+	}
+}
+
+// Verify with a single algorithm only checks that one.
+func TestVerifyAlgorithms(t *testing.T) {
+	key := []byte("secret")
+	m, err := Build(testTree(), Options{}, multi.Keyed(hashx.HMAC_SHA256, key), multi.Unkeyed(hashx.MD5))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Verify(testTree(), m, Options{}); err != hashx.ErrKeyRequired {
+		t.Fatalf("expected ErrKeyRequired, got %v", err)
+	}
+	if _, err := Verify(testTree(), m, Options{}, multi.Unkeyed(hashx.SHA1)); !errors.Is(err, ErrNotInManifest) {
+		t.Fatalf("expected ErrNotInManifest, got %v", err)
+	}
+
+	r, err := Verify(testTree(), m, Options{}, multi.Keyed("HMAC_SHA256", key))
+	if err != nil || !r.OK() {
+		t.Fatalf("keyed verify: %v\n%s", err, r)
+	}
+	r, err = Verify(testTree(), m, Options{}, multi.Keyed(hashx.HMAC_SHA256, []byte("wrong")))
+	if err != nil || len(r.Paths(Modified)) != len(m.Entries) {
+		t.Fatalf("wrong key must fail every file: %v\n%s", err, r)
+	}
+}
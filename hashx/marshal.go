@@ -0,0 +1,131 @@
+package hashx
+
+import (
+	"crypto/subtle"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// binaryVersion is the first byte of MarshalBinary output. It is not a printable
+// character, which lets Scan tell binary and text columns apart.
+const binaryVersion = 1
+
+// Equal reports whether both sums were made with the same algorithm and hold the
+// same digest. The digests are compared in constant time.
+func (h HashSum) Equal(other *HashSum) bool {
+	if other == nil || normalizeAlgName[HashAlgorithm](h.algo) != normalizeAlgName[HashAlgorithm](other.algo) {
+		return false
+	}
+	return len(h.data) == len(other.data) && subtle.ConstantTimeCompare(h.data, other.data) == 1
+}
+
+// IsZero reports whether h is the zero HashSum, for example one scanned from NULL.
+func (h HashSum) IsZero() bool {
+	return h.algo == "" && len(h.data) == 0
+}
+
+// String returns the algo:hex encoding of Encode.
+func (h HashSum) String() string {
+	return h.Encode()
+}
+
+// MarshalBinary encodes the sum compactly as a version byte, the length of the
+// algorithm name in a byte, the name and the raw digest.
+func (h HashSum) MarshalBinary() ([]byte, error) {
+	if h.algo == "" || len(h.algo) > 255 {
+		return nil, ErrInvalidEncoding
+	}
+	buf := make([]byte, 0, 2+len(h.algo)+len(h.data))
+	buf = append(buf, binaryVersion, byte(len(h.algo)))
+	buf = append(buf, h.algo...)
+	return append(buf, h.data...), nil
+}
+
+// UnmarshalBinary decodes the output of MarshalBinary, checking the algorithm is
+// registered and the digest has its size.
+func (h *HashSum) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 || data[0] != binaryVersion || len(data) < 2+int(data[1]) || data[1] == 0 {
+		return ErrInvalidEncoding
+	}
+	n := 2 + int(data[1])
+
+	sum, err := newHashSum(string(data[2:n]), append([]byte(nil), data[n:]...))
+	if err != nil {
+		return err
+	}
+	*h = *sum
+	return nil
+}
+
+// MarshalText returns the algo:hex encoding of Encode. The zero HashSum has no
+// text form and fails with ErrInvalidEncoding; MarshalJSON encodes it as null.
+func (h HashSum) MarshalText() ([]byte, error) {
+	if h.algo == "" {
+		return nil, ErrInvalidEncoding
+	}
+	return []byte(h.Encode()), nil
+}
+
+// UnmarshalText parses the algo:hex encoding, as ParseHashSum does.
+func (h *HashSum) UnmarshalText(text []byte) error {
+	sum, err := ParseHashSum(string(text))
+	if err != nil {
+		return err
+	}
+	*h = *sum
+	return nil
+}
+
+// MarshalJSON encodes the sum as an algo:hex JSON string. The zero HashSum is
+// null, as Value stores it as NULL.
+func (h HashSum) MarshalJSON() ([]byte, error) {
+	if h.IsZero() {
+		return []byte("null"), nil
+	}
+	text, err := h.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON parses an algo:hex JSON string. Like encoding/json itself, it
+// leaves h unchanged on null.
+func (h *HashSum) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidEncoding, err)
+	}
+	return h.UnmarshalText([]byte(text))
+}
+
+// Value stores the sum in a database in its binary form. The zero HashSum is NULL.
+func (h HashSum) Value() (driver.Value, error) {
+	if h.IsZero() {
+		return nil, nil
+	}
+	return h.MarshalBinary()
+}
+
+// Scan reads a sum stored by Value, or an algo:hex string as written by Encode.
+// NULL gives the zero HashSum.
+func (h *HashSum) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		*h = HashSum{}
+		return nil
+	case []byte:
+		if len(src) > 0 && src[0] == binaryVersion {
+			return h.UnmarshalBinary(src)
+		}
+		return h.UnmarshalText(src)
+	case string:
+		return h.UnmarshalText([]byte(src))
+	default:
+		return fmt.Errorf("%w: cannot scan %T into a HashSum", ErrInvalidEncoding, src)
+	}
+}
@@ -0,0 +1,190 @@
+package hashx
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+var (
+	_ encoding.BinaryMarshaler   = HashSum{}
+	_ encoding.BinaryUnmarshaler = (*HashSum)(nil)
+	_ encoding.TextMarshaler     = HashSum{}
+	_ encoding.TextUnmarshaler   = (*HashSum)(nil)
+	_ json.Marshaler             = HashSum{}
+	_ json.Unmarshaler           = (*HashSum)(nil)
+	_ driver.Valuer              = HashSum{}
+	_ sql.Scanner                = (*HashSum)(nil)
+)
+
+func TestHashSumEqual(t *testing.T) {
+	a, _ := HashString(SHA256, "abc")
+	b, _ := HashString(SHA256, "abc")
+	c, _ := HashString(SHA256, "abd")
+	d, _ := HashString(SHA3_256, "abc")
+
+	if !a.Equal(b) {
+		t.Fatal("equal sums differ")
+	}
+	if a.Equal(c) || a.Equal(d) || a.Equal(nil) {
+		t.Fatal("different sums are equal")
+	}
+
+	upper, err := NewHashSum("SHA256", a.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !a.Equal(upper) {
+		t.Fatal("algorithm names must be compared case insensitively")
+	}
+}
+
+func TestHashSumBinary(t *testing.T) {
+	for _, algo := range []HashAlgorithm{SHA256, MD5, SHA3_512} {
+		sum, _ := HashString(algo, "abc")
+		data, err := sum.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) != 2+len(algo)+len(sum.Bytes()) {
+			t.Fatalf("%s: %d bytes is not compact", algo, len(data))
+		}
+
+		var got HashSum
+		if err := got.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		if !got.Equal(sum) {
+			t.Fatalf("%s: round trip gave %s", algo, got)
+		}
+
+		// The decoded sum must not alias the input.
+		data[len(data)-1] ^= 1
+		if !got.Equal(sum) {
+			t.Fatal("decoded sum aliases its input")
+		}
+	}
+
+	sum, _ := HashString(SHA256, "abc")
+	data, _ := sum.MarshalBinary()
+	for name, bad := range map[string][]byte{
+		"empty":     nil,
+		"version":   append([]byte{2}, data[1:]...),
+		"truncated": data[:5],
+		"no name":   {binaryVersion, 0},
+	} {
+		var got HashSum
+		if err := got.UnmarshalBinary(bad); err != ErrInvalidEncoding {
+			t.Errorf("%s: expected ErrInvalidEncoding, got %v", name, err)
+		}
+	}
+
+	var got HashSum
+	var lengthErr *DigestLengthError
+	if err := got.UnmarshalBinary(data[:len(data)-1]); !errors.As(err, &lengthErr) {
+		t.Fatalf("expected a DigestLengthError, got %v", err)
+	}
+	if _, err := (HashSum{}).MarshalBinary(); err != ErrInvalidEncoding {
+		t.Fatalf("expected ErrInvalidEncoding, got %v", err)
+	}
+}
+
+func TestHashSumJSON(t *testing.T) {
+	sum, _ := HashString(SHA256, "abc")
+
+	type record struct {
+		Name string   `json:"name"`
+		Sum  *HashSum `json:"sum"`
+	}
+	data, err := json.Marshal(record{Name: "x", Sum: sum})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"name":"x","sum":"sha256:ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"}`
+	if string(data) != want {
+		t.Fatalf("got %s", data)
+	}
+
+	var got record
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.Sum.Equal(sum) {
+		t.Fatalf("round trip gave %s", got.Sum)
+	}
+
+	if err := json.Unmarshal([]byte(`{"sum":"nope:00"}`), &got); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+	if err := json.Unmarshal([]byte(`{"sum":12}`), &got); !errors.Is(err, ErrInvalidEncoding) {
+		t.Fatalf("expected ErrInvalidEncoding, got %v", err)
+	}
+
+	// The zero sum is null, as in SQL, while its text form is an error.
+	var zero struct {
+		Sum HashSum `json:"sum"`
+	}
+	if data, err := json.Marshal(zero); err != nil || string(data) != `{"sum":null}` {
+		t.Fatalf("got %s, %v", data, err)
+	}
+	if _, err := (HashSum{}).MarshalText(); err != ErrInvalidEncoding {
+		t.Fatalf("expected ErrInvalidEncoding, got %v", err)
+	}
+	if err := json.Unmarshal([]byte(`{"sum":null}`), &zero); err != nil || !zero.Sum.IsZero() {
+		t.Fatalf("expected the zero sum from null, got %v, %v", zero.Sum, err)
+	}
+	got.Sum = nil
+	if err := json.Unmarshal([]byte(`{"sum":null}`), &got); err != nil || got.Sum != nil {
+		t.Fatalf("expected a nil sum from null, got %v, %v", got.Sum, err)
+	}
+}
+
+func TestHashSumSQL(t *testing.T) {
+	sum, _ := HashString(SHA256, "abc")
+
+	v, err := sum.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, ok := v.([]byte)
+	if !ok || !driver.IsValue(v) {
+		t.Fatalf("Value returned %T", v)
+	}
+
+	for _, src := range []any{stored, sum.Encode(), []byte(sum.Encode())} {
+		var got HashSum
+		if err := got.Scan(src); err != nil {
+			t.Fatalf("Scan(%T): %v", src, err)
+		}
+		if !got.Equal(sum) {
+			t.Fatalf("Scan(%T) gave %s", src, got)
+		}
+	}
+
+	var got HashSum
+	if err := got.Scan(nil); err != nil || !got.IsZero() {
+		t.Fatalf("Scan(nil) gave %s, %v", got, err)
+	}
+	if v, err := got.Value(); v != nil || err != nil {
+		t.Fatalf("zero sum stored as %v, %v", v, err)
+	}
+	if err := got.Scan(int64(3)); !errors.Is(err, ErrInvalidEncoding) {
+		t.Fatalf("expected ErrInvalidEncoding, got %v", err)
+	}
+	if !bytes.Equal(stored, mustMarshal(t, sum)) {
+		t.Fatal("Value differs from MarshalBinary")
+	}
+}
+
+func mustMarshal(t *testing.T, sum *HashSum) []byte {
+	t.Helper()
+	data, err := sum.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
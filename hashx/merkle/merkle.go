@@ -0,0 +1,212 @@
+// Package merkle builds RFC 6962 style Merkle trees over hashx algorithms and
+// produces and verifies inclusion proofs for individual leaves.
+//
+// Leaves are hashed as H(0x00 || chunk) and inner nodes as H(0x01 || left || right),
+// so a tree over fixed size chunks has the same root as hashx.HashReaderParallel
+// with that chunk size. Roots are named by hashx.TreeAlgorithm, never by the plain
+// algorithm, since they are not digests of the data.
+package merkle
+
+import (
+	"errors"
+	"hash"
+	"io"
+
+	"github.com/atlastore/belt/hashx"
+)
+
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+var ErrIndexOutOfRange = errors.New("merkle: leaf index out of range")
+var ErrInvalidProof = errors.New("merkle: invalid proof")
+var ErrInvalidLeaf = errors.New("merkle: leaf hash has the wrong size")
+
+// Tree is a Merkle tree built from appended leaves.
+type Tree struct {
+	algo string
+	fn   func() hash.Hash
+	// chunkSize is set by FromReader, which splits the input into equal chunks.
+	chunkSize int
+
+	// levels[0] holds the leaf hashes, each following level the parents of the one below.
+	// An unpaired last node is carried up unchanged, which yields the RFC 6962 tree shape.
+	levels [][][]byte
+	dirty  bool
+}
+
+// New creates an empty tree hashed with algo. Extendable-output algorithms have no
+// fixed digest size to build a tree from and return hashx.ErrUnsupported.
+func New[T hashx.AnyHashAlgorithm](algo T, key ...[]byte) (*Tree, error) {
+	if class, _ := hashx.ClassOf(algo); class == hashx.ClassXOF || class == hashx.ClassKDF {
+		return nil, hashx.ErrUnsupported
+	}
+	fn, err := hashx.GetHash(algo, key...)
+	if err != nil {
+		return nil, err
+	}
+	return &Tree{
+		algo:   string(algo),
+		fn:     fn,
+		levels: [][][]byte{nil},
+	}, nil
+}
+
+// FromChunks builds a tree with one leaf per chunk.
+func FromChunks[T hashx.AnyHashAlgorithm](algo T, chunks [][]byte, key ...[]byte) (*Tree, error) {
+	t, err := New(algo, key...)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range chunks {
+		t.AddChunk(c)
+	}
+	return t, nil
+}
+
+// FromLeaves builds a tree from leaf hashes previously produced by LeafHash.
+func FromLeaves[T hashx.AnyHashAlgorithm](algo T, leaves [][]byte, key ...[]byte) (*Tree, error) {
+	t, err := New(algo, key...)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range leaves {
+		if err := t.AddLeaf(l); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// FromReader builds a tree over r split into chunkSize pieces. Only the last chunk may be shorter.
+func FromReader[T hashx.AnyHashAlgorithm](algo T, r io.Reader, chunkSize int, key ...[]byte) (*Tree, error) {
+	if chunkSize <= 0 {
+		return nil, hashx.ErrInvalidSize
+	}
+
+	t, err := New(algo, key...)
+	if err != nil {
+		return nil, err
+	}
+	t.chunkSize = chunkSize
+
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			t.AddChunk(buf[:n])
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return t, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// LeafHash returns the leaf hash of chunk.
+func (t *Tree) LeafHash(chunk []byte) []byte {
+	return leafHash(t.fn, chunk)
+}
+
+// AddChunk appends a leaf for chunk.
+func (t *Tree) AddChunk(chunk []byte) {
+	t.levels[0] = append(t.levels[0], t.LeafHash(chunk))
+	t.dirty = true
+}
+
+// AddLeaf appends a leaf hash produced by LeafHash.
+func (t *Tree) AddLeaf(leaf []byte) error {
+	if len(leaf) != t.fn().Size() {
+		return ErrInvalidLeaf
+	}
+	t.levels[0] = append(t.levels[0], append([]byte(nil), leaf...))
+	t.dirty = true
+	return nil
+}
+
+// Len returns the number of leaves.
+func (t *Tree) Len() int {
+	return len(t.levels[0])
+}
+
+// Root returns the root of the tree. The root of an empty tree is the hash of no input.
+// Its algorithm is hashx.TreeAlgorithm of the tree's algorithm, with the chunk size
+// for trees built by FromReader and without one otherwise.
+func (t *Tree) Root() *hashx.HashSum {
+	t.build()
+
+	var root []byte
+	if top := t.levels[len(t.levels)-1]; len(top) == 1 {
+		root = top[0]
+	} else {
+		root = t.fn().Sum(nil)
+	}
+
+	// New only accepts algorithms with a fixed digest size, which NewHashSum
+	// accepts as the base of a tree algorithm.
+	sum, err := hashx.NewHashSum(hashx.TreeAlgorithm(t.algo, t.chunkSize), root)
+	if err != nil {
+		panic(err)
+	}
+	return sum
+}
+
+// Prove returns the inclusion proof for the leaf at index.
+func (t *Tree) Prove(index int) (*Proof, error) {
+	if index < 0 || index >= t.Len() {
+		return nil, ErrIndexOutOfRange
+	}
+	t.build()
+
+	p := &Proof{
+		Index: uint64(index),
+		Size:  uint64(t.Len()),
+	}
+	for _, level := range t.levels[:len(t.levels)-1] {
+		if sibling := index ^ 1; sibling < len(level) {
+			p.Path = append(p.Path, append([]byte(nil), level[sibling]...))
+		}
+		index /= 2
+	}
+	return p, nil
+}
+
+func (t *Tree) build() {
+	if !t.dirty {
+		return
+	}
+
+	t.levels = t.levels[:1]
+	for level := t.levels[0]; len(level) > 1; {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			next = append(next, nodeHash(t.fn, level[i], level[i+1]))
+		}
+		t.levels = append(t.levels, next)
+		level = next
+	}
+	t.dirty = false
+}
+
+func leafHash(fn func() hash.Hash, chunk []byte) []byte {
+	h := fn()
+	h.Write([]byte{leafPrefix})
+	h.Write(chunk)
+	return h.Sum(nil)
+}
+
+func nodeHash(fn func() hash.Hash, left, right []byte) []byte {
+	h := fn()
+	h.Write([]byte{nodePrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
@@ -0,0 +1,129 @@
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/atlastore/belt/hashx"
+)
+
+// mth is the recursive Merkle Tree Hash definition from RFC 6962 section 2.1.
+func mth(chunks [][]byte) []byte {
+	switch len(chunks) {
+	case 0:
+		s := sha256.Sum256(nil)
+		return s[:]
+	case 1:
+		s := sha256.Sum256(append([]byte{leafPrefix}, chunks[0]...))
+		return s[:]
+	}
+	k := 1
+	for k*2 < len(chunks) {
+		k *= 2
+	}
+	s := sha256.Sum256(append(append([]byte{nodePrefix}, mth(chunks[:k])...), mth(chunks[k:])...))
+	return s[:]
+}
+
+func TestTree(t *testing.T) {
+	for n := 0; n <= 20; n++ {
+		chunks := make([][]byte, n)
+		for i := range chunks {
+			chunks[i] = []byte(fmt.Sprintf("chunk-%d", i))
+		}
+
+		tree, err := FromChunks(hashx.SHA256, chunks)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		root := tree.Root()
+		if !bytes.Equal(root.Bytes(), mth(chunks)) {
+			t.Fatalf("n=%d: root does not match RFC 6962", n)
+		}
+
+		for i := range chunks {
+			proof, err := tree.Prove(i)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := Verify(root, chunks[i], proof); err != nil {
+				t.Fatalf("n=%d i=%d: %v", n, i, err)
+			}
+			if err := Verify(root, []byte("tampered"), proof); err != hashx.ErrMismatch {
+				t.Fatalf("n=%d i=%d: expected ErrMismatch, got %v", n, i, err)
+			}
+
+			buf, err := proof.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var decoded Proof
+			if err := decoded.UnmarshalBinary(buf); err != nil {
+				t.Fatal(err)
+			}
+			if err := VerifyLeaf(root, tree.LeafHash(chunks[i]), &decoded); err != nil {
+				t.Fatalf("n=%d i=%d: decoded proof: %v", n, i, err)
+			}
+
+			if n > 1 {
+				wrong := *proof
+				wrong.Index = uint64((i + 1) % n)
+				if err := Verify(root, chunks[i], &wrong); err == nil {
+					t.Fatalf("n=%d i=%d: proof verified at the wrong index", n, i)
+				}
+			}
+		}
+	}
+}
+
+// The tree duplicates the Merkle construction of hashx.HashReaderParallel; both
+// must keep producing the same root for every shape of input.
+func TestTreeMatchesParallel(t *testing.T) {
+	data := bytes.Repeat([]byte("replicated object "), 1000)
+	const chunkSize = 1000
+
+	for _, size := range []int{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, 2 * chunkSize, 5*chunkSize + 3, len(data)} {
+		tree, err := FromReader(hashx.SHA256, bytes.NewReader(data[:size]), chunkSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		opts := hashx.ParallelOptions{ChunkSize: chunkSize, Workers: 3}
+		parallel, err := hashx.HashReaderParallel(hashx.SHA256, bytes.NewReader(data[:size]), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parallelAt, err := hashx.HashReaderAtParallel(hashx.SHA256, bytes.NewReader(data[:size]), int64(size), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		root := tree.Root()
+		if root.Encode() != parallel.Encode() || root.Encode() != parallelAt.Encode() {
+			t.Fatalf("size %d: tree root %s, parallel %s, parallel ReaderAt %s", size, root.Encode(), parallel.Encode(), parallelAt.Encode())
+		}
+	}
+}
+
+// Without a fixed digest size a root cannot be named, so XOFs are rejected up front.
+func TestTreeRejectsXOF(t *testing.T) {
+	for _, algo := range []hashx.XOFAlgorithm{hashx.SHAKE128, hashx.SHAKE256, hashx.Blake3XOF} {
+		if _, err := New(algo); err != hashx.ErrUnsupported {
+			t.Fatalf("%s: expected ErrUnsupported, got %v", algo, err)
+		}
+		if _, err := FromReader(algo, bytes.NewReader([]byte("data")), 2); err != hashx.ErrUnsupported {
+			t.Fatalf("%s: expected ErrUnsupported, got %v", algo, err)
+		}
+	}
+
+	tree, err := FromChunks(hashx.Blake3, [][]byte{[]byte("a"), []byte("b")}, make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root := tree.Root(); root == nil || root.Algorithm() != "blake3-tree" {
+		t.Fatalf("unexpected root %v", root)
+	}
+}
@@ -0,0 +1,127 @@
+package merkle
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+	"hash"
+
+	"github.com/atlastore/belt/hashx"
+)
+
+// Proof is an RFC 6962 audit path for one leaf of a tree of Size leaves.
+type Proof struct {
+	Index uint64
+	Size  uint64
+	// Path holds the sibling hashes from the leaf up to the root.
+	Path [][]byte
+}
+
+// Verify checks that chunk is the leaf at proof.Index of the tree with the given root.
+// It returns hashx.ErrMismatch if the proof does not lead to root.
+func Verify(root *hashx.HashSum, chunk []byte, proof *Proof, key ...[]byte) error {
+	fn, err := rootHash(root, key...)
+	if err != nil {
+		return err
+	}
+	return verify(fn, root, leafHash(fn, chunk), proof)
+}
+
+// VerifyLeaf is Verify for a leaf hash produced by LeafHash.
+func VerifyLeaf(root *hashx.HashSum, leaf []byte, proof *Proof, key ...[]byte) error {
+	fn, err := rootHash(root, key...)
+	if err != nil {
+		return err
+	}
+	return verify(fn, root, leaf, proof)
+}
+
+// rootHash returns the hash function of the tree with the given root.
+func rootHash(root *hashx.HashSum, key ...[]byte) (func() hash.Hash, error) {
+	algo := root.Algorithm()
+	if base, _, ok := hashx.ParseTreeAlgorithm(algo); ok {
+		algo = base
+	}
+	return hashx.GetHash(algo, key...)
+}
+
+// verify follows the algorithm in RFC 9162 section 2.1.3.2.
+func verify(fn func() hash.Hash, root *hashx.HashSum, leaf []byte, proof *Proof) error {
+	if proof == nil || proof.Index >= proof.Size {
+		return ErrInvalidProof
+	}
+
+	index, last := proof.Index, proof.Size-1
+	r := leaf
+	for _, p := range proof.Path {
+		if last == 0 {
+			return ErrInvalidProof
+		}
+		if index&1 == 1 || index == last {
+			r = nodeHash(fn, p, r)
+			for index&1 == 0 && index != 0 {
+				index >>= 1
+				last >>= 1
+			}
+		} else {
+			r = nodeHash(fn, r, p)
+		}
+		index >>= 1
+		last >>= 1
+	}
+
+	if last != 0 {
+		return ErrInvalidProof
+	}
+	if subtle.ConstantTimeCompare(r, root.Bytes()) != 1 {
+		return hashx.ErrMismatch
+	}
+	return nil
+}
+
+// MarshalBinary encodes the proof as varint index, size and path length, followed by
+// the digest size and the path digests.
+func (p *Proof) MarshalBinary() ([]byte, error) {
+	size := 0
+	if len(p.Path) > 0 {
+		size = len(p.Path[0])
+	}
+
+	buf := make([]byte, 0, 4*binary.MaxVarintLen64+size*len(p.Path))
+	buf = binary.AppendUvarint(buf, p.Index)
+	buf = binary.AppendUvarint(buf, p.Size)
+	buf = binary.AppendUvarint(buf, uint64(len(p.Path)))
+	buf = binary.AppendUvarint(buf, uint64(size))
+	for _, node := range p.Path {
+		if len(node) != size {
+			return nil, fmt.Errorf("%w: path digests differ in size", ErrInvalidProof)
+		}
+		buf = append(buf, node...)
+	}
+	return buf, nil
+}
+
+func (p *Proof) UnmarshalBinary(buf []byte) error {
+	var fields [4]uint64
+	for i := range fields {
+		v, n := binary.Uvarint(buf)
+		if n <= 0 {
+			return ErrInvalidProof
+		}
+		fields[i] = v
+		buf = buf[n:]
+	}
+
+	count, size := fields[2], fields[3]
+	if count > 64 || size > uint64(len(buf)) || count*size != uint64(len(buf)) {
+		return ErrInvalidProof
+	}
+
+	p.Index, p.Size = fields[0], fields[1]
+	p.Path = make([][]byte, count)
+	for i := range p.Path {
+		p.Path[i] = append([]byte(nil), buf[:size]...)
+		buf = buf[size:]
+	}
+	return nil
+}
@@ -5,8 +5,12 @@ import (
 	"math/big"
 )
 
-
+// Mod reduces the whole digest modulo mod. It returns 0 if mod is not positive;
+// Bucket and JumpHash are unbiased or consistent alternatives that return an error for invalid input.
 func Mod(data []byte, mod int64) int64 {
+	if mod <= 0 {
+		return 0
+	}
 	hashInt := new(big.Int).SetBytes(data[:])
 
 	modVal := new(big.Int).Mod(hashInt, big.NewInt(mod))
@@ -14,7 +18,12 @@ func Mod(data []byte, mod int64) int64 {
 	return modVal.Int64()
 }
 
+// QuickMod reduces the first 8 bytes of the digest modulo mod, or returns 0 if mod
+// is not positive. The result is modulo-biased; prefer HashSum.Bucket or HashSum.Jump.
 func QuickMod(data []byte, mod int64) int64 {
+	if mod <= 0 {
+		return 0
+	}
 	buf := make([]byte, 8)
 	if len(data) > 8 {
 		copy(buf, data[:8])
@@ -27,4 +36,4 @@ func QuickMod(data []byte, mod int64) int64 {
 	modVal := hashInt % uint64(mod)
 
 	return int64(modVal)
-}
\ No newline at end of file
+}
@@ -0,0 +1,171 @@
+package multi
+
+import (
+	"hash"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/atlastore/belt/hashx"
+)
+
+const concurrentBufSize = 128 * 1024
+
+// concurrentDepth is how many buffers each algorithm may lag behind the reader
+// before writes block.
+const concurrentDepth = 4
+
+var concurrentBufPool = sync.Pool{
+	New: func() any { return &sharedBuf{data: make([]byte, concurrentBufSize)} },
+}
+
+// sharedBuf is handed to every worker and returned to the pool by the last one done with it.
+type sharedBuf struct {
+	data []byte
+	n    int
+	refs atomic.Int32
+}
+
+func (b *sharedBuf) release() {
+	if b.refs.Add(-1) == 0 {
+		concurrentBufPool.Put(b)
+	}
+}
+
+// ConcurrentWriter feeds every hasher from its own goroutine, so the wall time of
+// hashing with several algorithms is that of the slowest one rather than their sum.
+// Writes are copied into pooled buffers; a Write blocks once the slowest hasher
+// is concurrentDepth buffers behind. Close must be called before reading the sums.
+type ConcurrentWriter struct {
+	queues []chan *sharedBuf
+	wg     sync.WaitGroup
+	closed bool
+}
+
+func newConcurrentWriter(hashers []hash.Hash) *ConcurrentWriter {
+	cw := &ConcurrentWriter{
+		queues: make([]chan *sharedBuf, len(hashers)),
+	}
+	for i, h := range hashers {
+		q := make(chan *sharedBuf, concurrentDepth)
+		cw.queues[i] = q
+		cw.wg.Add(1)
+		go func(h hash.Hash) {
+			defer cw.wg.Done()
+			for b := range q {
+				h.Write(b.data[:b.n])
+				b.release()
+			}
+		}(h)
+	}
+	return cw
+}
+
+// Write hands p to every hasher. It fails only with io.ErrClosedPipe after Close,
+// since hash.Hash writes do not return errors.
+func (cw *ConcurrentWriter) Write(p []byte) (int, error) {
+	if cw.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	written := len(p)
+	for len(p) > 0 {
+		b := concurrentBufPool.Get().(*sharedBuf)
+		b.n = copy(b.data, p)
+		p = p[b.n:]
+		cw.dispatch(b)
+	}
+	return written, nil
+}
+
+// ReadFrom reads straight into pooled buffers, avoiding the copy made by Write.
+func (cw *ConcurrentWriter) ReadFrom(r io.Reader) (int64, error) {
+	if cw.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	var total int64
+	for {
+		b := concurrentBufPool.Get().(*sharedBuf)
+		n, err := io.ReadFull(r, b.data)
+		total += int64(n)
+		if n > 0 {
+			b.n = n
+			cw.dispatch(b)
+		} else {
+			concurrentBufPool.Put(b)
+		}
+
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return total, nil
+		}
+		if err != nil {
+			return total, err
+		}
+	}
+}
+
+func (cw *ConcurrentWriter) dispatch(b *sharedBuf) {
+	if len(cw.queues) == 0 {
+		concurrentBufPool.Put(b)
+		return
+	}
+	b.refs.Store(int32(len(cw.queues)))
+	for _, q := range cw.queues {
+		q <- b
+	}
+}
+
+// Close waits for every hasher to consume the data written so far.
+func (cw *ConcurrentWriter) Close() error {
+	if cw.closed {
+		return nil
+	}
+	cw.closed = true
+	for _, q := range cw.queues {
+		close(q)
+	}
+	cw.wg.Wait()
+	return nil
+}
+
+// ConcurrentWriter returns a writer that feeds each hasher from its own goroutine.
+func (ah *algoHasher[T]) ConcurrentWriter() *ConcurrentWriter {
+	return newConcurrentWriter(ah.hashers)
+}
+
+// HashReaderConcurrent is HashReader with one goroutine per algorithm.
+// The results are identical to HashReader.
+func HashReaderConcurrent[T hashx.AnyHashAlgorithm](r io.Reader, algs ...T) ([]string, error) {
+	hs, err := NewHashers(algs...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := copyConcurrent(hs.hashers, r); err != nil {
+		return nil, err
+	}
+
+	return finalizeHashes(hs), nil
+}
+
+// HashReaderSumsConcurrent is HashReaderSums with one goroutine per algorithm.
+func HashReaderSumsConcurrent(r io.Reader, specs ...Spec) ([]*hashx.HashSum, error) {
+	hs, err := NewHashersWithKeys(specs...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := copyConcurrent(hs.hashers, r); err != nil {
+		return nil, err
+	}
+
+	return finalizeSums(hs)
+}
+
+func copyConcurrent(hashers []hash.Hash, r io.Reader) error {
+	cw := newConcurrentWriter(hashers)
+	_, err := cw.ReadFrom(r)
+	cw.Close()
+	return err
+}
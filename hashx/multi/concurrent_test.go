@@ -0,0 +1,97 @@
+package multi
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+
+	"github.com/atlastore/belt/hashx"
+)
+
+func TestConcurrentMatchesSerial(t *testing.T) {
+	data := make([]byte, 3*concurrentBufSize+12345)
+	rand.Read(data)
+
+	for _, size := range []int{0, 1, concurrentBufSize, len(data)} {
+		serial, err := HashReader(bytes.NewReader(data[:size]), hashx.SHA512, hashx.MD5, hashx.SHA3_256, hashx.XXHash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		concurrent, err := HashReaderConcurrent(bytes.NewReader(data[:size]), hashx.SHA512, hashx.MD5, hashx.SHA3_256, hashx.XXHash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range serial {
+			if serial[i] != concurrent[i] {
+				t.Fatalf("size %d: expected %s, got %s", size, serial[i], concurrent[i])
+			}
+		}
+	}
+
+	key := make([]byte, 64)
+	specs := []Spec{Keyed(hashx.Blake2b, key), Unkeyed(hashx.SHA256)}
+	serial, err := HashReaderSums(bytes.NewReader(data), specs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	concurrent, err := HashReaderSumsConcurrent(bytes.NewReader(data), specs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range serial {
+		if serial[i].Encode() != concurrent[i].Encode() {
+			t.Fatalf("expected %s, got %s", serial[i].Encode(), concurrent[i].Encode())
+		}
+	}
+}
+
+func TestConcurrentWriter(t *testing.T) {
+	data := make([]byte, 2*concurrentBufSize+7)
+	rand.Read(data)
+
+	hs, err := NewHashers(hashx.SHA256, hashx.MD5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cw := hs.ConcurrentWriter()
+	// Odd sized writes, some larger than one pooled buffer.
+	for r := bytes.NewReader(data); r.Len() > 0; {
+		chunk := make([]byte, 100000)
+		n, _ := r.Read(chunk)
+		cw.Write(chunk[:n])
+	}
+	cw.Close()
+
+	if _, err := cw.Write([]byte("late")); err != io.ErrClosedPipe {
+		t.Fatalf("expected io.ErrClosedPipe, got %v", err)
+	}
+
+	want, _ := Hash(data, hashx.SHA256, hashx.MD5)
+	got := hs.Sum()
+	for i := range want {
+		if want[i] != got[i] {
+			t.Fatalf("expected %s, got %s", want[i], got[i])
+		}
+	}
+}
+
+func BenchmarkHashReader(b *testing.B) {
+	data := make([]byte, 64<<20)
+	rand.Read(data)
+	specs := []Spec{Unkeyed(hashx.SHA512), Keyed(hashx.Blake2b, make([]byte, 64)), Unkeyed(hashx.MD5)}
+
+	b.Run("serial", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			HashReaderSums(bytes.NewReader(data), specs...)
+		}
+	})
+	b.Run("concurrent", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			HashReaderSumsConcurrent(bytes.NewReader(data), specs...)
+		}
+	})
+}
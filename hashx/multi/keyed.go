@@ -0,0 +1,75 @@
+package multi
+
+import (
+	"hash"
+	"io"
+
+	"github.com/atlastore/belt/hashx"
+)
+
+// Spec pairs an algorithm with the key it needs, so keyed and unkeyed
+// algorithms of any class can be computed in the same pass.
+type Spec struct {
+	Algorithm string
+	Key       []byte
+}
+
+// Keyed returns a Spec for a keyed algorithm.
+func Keyed[T hashx.AnyHashAlgorithm](algo T, key []byte) Spec {
+	return Spec{Algorithm: string(algo), Key: key}
+}
+
+// Unkeyed returns a Spec for an algorithm that takes no key.
+func Unkeyed[T hashx.AnyHashAlgorithm](algo T) Spec {
+	return Spec{Algorithm: string(algo)}
+}
+
+// NewHashersWithKeys is NewHashers for a mix of keyed and unkeyed algorithms.
+func NewHashersWithKeys(specs ...Spec) (algoHasher[string], error) {
+	hs := algoHasher[string]{
+		algos:   make([]string, len(specs)),
+		hashers: make([]hash.Hash, len(specs)),
+	}
+	for i, spec := range specs {
+		var key [][]byte
+		if spec.Key != nil {
+			key = append(key, spec.Key)
+		}
+		fn, err := hashx.GetHash(spec.Algorithm, key...)
+		if err != nil {
+			return algoHasher[string]{}, err
+		}
+		hs.algos[i] = spec.Algorithm
+		hs.hashers[i] = fn()
+	}
+	return hs, nil
+}
+
+// HashSums hashes data with every spec in one pass.
+func HashSums(data []byte, specs ...Spec) ([]*hashx.HashSum, error) {
+	hs, err := NewHashersWithKeys(specs...)
+	if err != nil {
+		return nil, err
+	}
+
+	mw := io.MultiWriter(toWriters(hs.hashers)...)
+	if _, err := mw.Write(data); err != nil {
+		return nil, err
+	}
+
+	return finalizeSums(hs)
+}
+
+// HashReaderSums hashes everything read from r with every spec in one pass.
+func HashReaderSums(r io.Reader, specs ...Spec) ([]*hashx.HashSum, error) {
+	hs, err := NewHashersWithKeys(specs...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := copyBuffered(io.MultiWriter(toWriters(hs.hashers)...), r); err != nil {
+		return nil, err
+	}
+
+	return finalizeSums(hs)
+}
@@ -0,0 +1,92 @@
+package multi
+
+import (
+	"crypto/sha256"
+	"hash"
+	"strings"
+	"testing"
+
+	"github.com/atlastore/belt/hashx"
+)
+
+func TestKeyed(t *testing.T) {
+	data := "signed upload body"
+	key := []byte("upload-signing-key")
+
+	sums, err := HashReaderSums(strings.NewReader(data),
+		Keyed(hashx.HMAC_SHA256, key),
+		Unkeyed(hashx.SHA256),
+		Unkeyed(hashx.FNV64),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mac, _ := hashx.HashString(hashx.HMAC_SHA256, data, key)
+	plain, _ := hashx.HashString(hashx.SHA256, data)
+	fnv, _ := hashx.HashString(hashx.FNV64, data)
+
+	for i, want := range []*hashx.HashSum{mac, plain, fnv} {
+		if sums[i].Encode() != want.Encode() {
+			t.Fatalf("%d: expected %s, got %s", i, want.Encode(), sums[i].Encode())
+		}
+	}
+
+	if _, err := HashSums([]byte(data), Unkeyed(hashx.HMAC_SHA256)); err != hashx.ErrKeyRequired {
+		t.Fatalf("expected ErrKeyRequired, got %v", err)
+	}
+}
+
+func TestSumMap(t *testing.T) {
+	hs, err := NewHashers(hashx.SHA256, hashx.MD5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, w := range hs.Writers() {
+		w.Write([]byte("abc"))
+	}
+
+	sums, err := hs.SumMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sums["md5"].Encode(); got != "md5:900150983cd24fb0d6963f7d28e17f72" {
+		t.Fatalf("unexpected md5 %s", got)
+	}
+	list, err := hs.Sums()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := list[0].Encode(); got != sums["sha256"].Encode() {
+		t.Fatalf("Sums and SumMap disagree: %s", got)
+	}
+	if strings.Join(hs.Sum(), ",") == "" {
+		t.Fatal("expected encoded sums")
+	}
+}
+
+// shortSize reports a smaller size than the digest it returns, so NewHashSum rejects it.
+type shortSize struct{ hash.Hash }
+
+func (shortSize) Size() int { return 4 }
+
+func TestSumsError(t *testing.T) {
+	const algo = "multi-test-short-size"
+	if !hashx.IsRegistered(algo) {
+		hashx.Register(algo, func() hash.Hash { return shortSize{sha256.New()} })
+	}
+
+	if sums, err := HashSums([]byte("data"), Unkeyed(hashx.HashAlgorithm(algo))); err == nil {
+		t.Fatalf("expected an error, got %v", sums)
+	}
+	hs, err := NewHashers(hashx.HashAlgorithm(algo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := hs.Sums(); err == nil {
+		t.Fatal("Sums returned no error")
+	}
+	if _, err := hs.SumMap(); err == nil {
+		t.Fatal("SumMap returned no error")
+	}
+}
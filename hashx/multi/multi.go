@@ -38,28 +38,31 @@ func HashReader[T hashx.AnyHashAlgorithm](r io.Reader, algs ...T) ([]string, err
 		return nil, err
 	}
 
-	mw := io.MultiWriter(toWriters(hs.hashers)...)
+	if err := copyBuffered(io.MultiWriter(toWriters(hs.hashers)...), r); err != nil {
+		return nil, err
+	}
+
+	return finalizeHashes(hs), nil
+}
 
+func copyBuffered(w io.Writer, r io.Reader) error {
 	buf := readerBufPool.Get().([]byte)
 	defer readerBufPool.Put(buf)
 
 	for {
 		nr, er := r.Read(buf)
 		if nr > 0 {
-			if _, ew := mw.Write(buf[:nr]); ew != nil {
-				return nil, ew
+			if _, ew := w.Write(buf[:nr]); ew != nil {
+				return ew
 			}
 		}
 		if er != nil {
 			if er == io.EOF {
-				break
+				return nil
 			}
-			return nil, er
+			return er
 		}
 	}
-
-
-	return finalizeHashes(hs), nil
 }
 
 type algoHasher[T hashx.AnyHashAlgorithm] struct {
@@ -74,6 +77,8 @@ func (ah *algoHasher[T]) Len() int {
 	 return len(ah.algos)
 }
 
+// NewHashers creates one hasher per algorithm. Keyed algorithms are rejected with
+// hashx.ErrKeyRequired; use NewHashersWithKeys to pass their keys.
 func NewHashers[T hashx.AnyHashAlgorithm](algs ...T) (algoHasher[T], error) {
 	hs := algoHasher[T]{
 		algos: make([]T, len(algs)),
@@ -104,6 +109,18 @@ func finalizeHashes[T hashx.AnyHashAlgorithm](hs algoHasher[T]) []string {
 	return results
 }
 
+func finalizeSums[T hashx.AnyHashAlgorithm](hs algoHasher[T]) ([]*hashx.HashSum, error) {
+	results := make([]*hashx.HashSum, hs.Len())
+	for i, h := range hs.hashers {
+		sum, err := hashx.NewHashSum(hs.algos[i], h.Sum(nil))
+		if err != nil {
+			return nil, err
+		}
+		results[i] = sum
+	}
+	return results, nil
+}
+
 func toWriters(hs []hash.Hash) []io.Writer {
 	ws := make([]io.Writer, len(hs))
 	for i, h := range hs {
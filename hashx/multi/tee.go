@@ -0,0 +1,53 @@
+package multi
+
+import (
+	"io"
+
+	"github.com/atlastore/belt/hashx"
+)
+
+// TeeWriter writes to an underlying writer and hashes exactly the bytes it accepted
+// with every algorithm of a hasher set. It is the multi-digest form of hashx.TeeWriter.
+type TeeWriter[T hashx.AnyHashAlgorithm] struct {
+	w       io.Writer
+	hs      algoHasher[T]
+	written int64
+}
+
+// NewTeeWriter creates a TeeWriter for a mix of keyed and unkeyed algorithms.
+func NewTeeWriter(w io.Writer, specs ...Spec) (*TeeWriter[string], error) {
+	hs, err := NewHashersWithKeys(specs...)
+	if err != nil {
+		return nil, err
+	}
+	return hs.TeeWriter(w), nil
+}
+
+// TeeWriter returns a writer that forwards to w and feeds the hasher set.
+func (ah *algoHasher[T]) TeeWriter(w io.Writer) *TeeWriter[T] {
+	return &TeeWriter[T]{w: w, hs: *ah}
+}
+
+func (tw *TeeWriter[T]) Write(p []byte) (int, error) {
+	n, err := tw.w.Write(p)
+	for _, h := range tw.hs.hashers {
+		h.Write(p[:n])
+	}
+	tw.written += int64(n)
+	return n, err
+}
+
+// Written returns the number of bytes written so far.
+func (tw *TeeWriter[T]) Written() int64 {
+	return tw.written
+}
+
+// Sums returns the digests of everything written so far, in the order the algorithms were given.
+func (tw *TeeWriter[T]) Sums() ([]*hashx.HashSum, error) {
+	return tw.hs.Sums()
+}
+
+// SumMap returns the digests keyed by algorithm name.
+func (tw *TeeWriter[T]) SumMap() (map[string]*hashx.HashSum, error) {
+	return tw.hs.SumMap()
+}
@@ -0,0 +1,39 @@
+package multi
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/atlastore/belt/hashx"
+)
+
+func TestTeeWriter(t *testing.T) {
+	key := []byte("replication key")
+	data := bytes.Repeat([]byte("block"), 10000)
+
+	var buf bytes.Buffer
+	tw, err := NewTeeWriter(&buf, Unkeyed(hashx.SHA256), Keyed(hashx.Blake2b, key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tw.Write(data[:123])
+	tw.Write(data[123:])
+
+	if !bytes.Equal(buf.Bytes(), data) || tw.Written() != int64(len(data)) {
+		t.Fatal("expected the data to pass through unchanged")
+	}
+
+	want, _ := HashSums(data, Unkeyed(hashx.SHA256), Keyed(hashx.Blake2b, key))
+	sums, err := tw.Sums()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, sum := range sums {
+		if sum.Encode() != want[i].Encode() {
+			t.Fatalf("expected %s, got %s", want[i].Encode(), sum.Encode())
+		}
+	}
+	if m, err := tw.SumMap(); err != nil || m[string(hashx.SHA256)].Encode() != want[0].Encode() {
+		t.Fatal("SumMap does not match Sums")
+	}
+}
@@ -2,6 +2,8 @@ package multi
 
 import (
 	"io"
+
+	"github.com/atlastore/belt/hashx"
 )
 
 func (ah *algoHasher[T]) Writers() []io.Writer {
@@ -10,4 +12,24 @@ func (ah *algoHasher[T]) Writers() []io.Writer {
 
 func (ah *algoHasher[T]) Sum() []string {
 	return finalizeHashes(*ah)
-}
\ No newline at end of file
+}
+
+// Sums returns the digests in the order the algorithms were given. It fails if
+// a hasher returns a digest that does not fit its algorithm.
+func (ah *algoHasher[T]) Sums() ([]*hashx.HashSum, error) {
+	return finalizeSums(*ah)
+}
+
+// SumMap returns the digests keyed by algorithm name.
+// If an algorithm was given more than once, with different keys, the last one wins.
+func (ah *algoHasher[T]) SumMap() (map[string]*hashx.HashSum, error) {
+	sums, err := finalizeSums(*ah)
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[string]*hashx.HashSum, len(sums))
+	for i, sum := range sums {
+		results[string(ah.algos[i])] = sum
+	}
+	return results, nil
+}
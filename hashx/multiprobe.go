@@ -0,0 +1,145 @@
+package hashx
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/atlastore/belt/hashx/internal/mix"
+)
+
+const DefaultProbes = 21
+
+// MultiProbe implements multi-probe consistent hashing (Appleton and O'Reilly).
+// Each member sits on the ring at one point per unit of weight and every key is
+// hashed several times; the key belongs to the member whose point follows any
+// probe most closely. It balances as well as a ring with many virtual nodes
+// while keeping only one point per member.
+// It is safe for concurrent use.
+type MultiProbe struct {
+	mu      sync.RWMutex
+	algo    Hash64Algorithm
+	probes  int
+	weights map[string]int
+	points  []ringPoint
+}
+
+// NewMultiProbe creates an empty multi-probe hasher. probes defaults to DefaultProbes.
+func NewMultiProbe(algo Hash64Algorithm, probes ...int) (*MultiProbe, error) {
+	if _, err := getHash64Func(algo); err != nil {
+		return nil, err
+	}
+
+	n := DefaultProbes
+	if len(probes) == 1 && probes[0] > 0 {
+		n = probes[0]
+	}
+
+	return &MultiProbe{
+		algo:    algo,
+		probes:  n,
+		weights: make(map[string]int),
+	}, nil
+}
+
+func (m *MultiProbe) Add(member string, weight int) error {
+	if weight <= 0 {
+		return ErrInvalidWeight
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.weights[member] = weight
+	m.rebuild()
+	return nil
+}
+
+func (m *MultiProbe) Remove(member string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.weights[member]; !ok {
+		return
+	}
+	delete(m.weights, member)
+	m.rebuild()
+}
+
+func (m *MultiProbe) Members() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return sortedMembers(m.weights)
+}
+
+func (m *MultiProbe) Get(key []byte) (string, bool) {
+	members := m.GetN(key, 1)
+	if len(members) == 0 {
+		return "", false
+	}
+	return members[0], true
+}
+
+// GetN ranks members by the smallest clockwise distance from any probe to one of
+// their points and returns the closest n.
+func (m *MultiProbe) GetN(key []byte, n int) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if len(m.points) == 0 || n <= 0 {
+		return nil
+	}
+	n = min(n, len(m.weights))
+
+	best := make(map[string]uint64, n)
+	h := m.algo.HashBytes(key)
+	for i := 0; i < m.probes; i++ {
+		probe := mix.Mix64(h + uint64(i)*0x9e3779b97f4a7c15)
+		start := sort.Search(len(m.points), func(j int) bool {
+			return m.points[j].hash >= probe
+		})
+
+		// Only the first n distinct members after a probe can be among the n closest.
+		walked := make([]string, 0, n)
+		for j := 0; j < len(m.points) && len(walked) < n; j++ {
+			p := m.points[(start+j)%len(m.points)]
+			if contains(walked, p.member) {
+				continue
+			}
+			walked = append(walked, p.member)
+
+			dist := p.hash - probe
+			if d, ok := best[p.member]; !ok || dist < d {
+				best[p.member] = dist
+			}
+		}
+	}
+
+	members := make([]string, 0, len(best))
+	for member := range best {
+		members = append(members, member)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if best[members[i]] == best[members[j]] {
+			return members[i] < members[j]
+		}
+		return best[members[i]] < best[members[j]]
+	})
+	return members[:n]
+}
+
+func (m *MultiProbe) rebuild() {
+	m.points = m.points[:0]
+	for member, weight := range m.weights {
+		for i := 0; i < weight; i++ {
+			m.points = append(m.points, ringPoint{
+				hash:   mix.Mix64(m.algo.HashString(member + "#" + strconv.Itoa(i))),
+				member: member,
+			})
+		}
+	}
+	sort.Slice(m.points, func(i, j int) bool {
+		if m.points[i].hash == m.points[j].hash {
+			return m.points[i].member < m.points[j].member
+		}
+		return m.points[i].hash < m.points[j].hash
+	})
+}
@@ -0,0 +1,364 @@
+package hashx
+
+import (
+	"bytes"
+	"errors"
+	"hash"
+	"io"
+	"math/bits"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"lukechampine.com/blake3/guts"
+)
+
+const DefaultParallelChunkSize = 1 << 20
+
+// ParallelOptions configures HashReaderParallel and HashReaderAtParallel.
+type ParallelOptions struct {
+	// ChunkSize is the number of bytes hashed by a worker at a time. Defaults to DefaultParallelChunkSize.
+	// BLAKE3 rounds it up to a power of two of at least 1 KiB.
+	ChunkSize int
+	// Workers is the number of goroutines used. Defaults to runtime.GOMAXPROCS(0).
+	Workers int
+}
+
+func (o ParallelOptions) withDefaults() ParallelOptions {
+	if o.ChunkSize <= 0 {
+		o.ChunkSize = DefaultParallelChunkSize
+	}
+	if o.Workers <= 0 {
+		o.Workers = runtime.GOMAXPROCS(0)
+	}
+	return o
+}
+
+// Merkle node prefixes, as in RFC 6962, so leaves and inner nodes never collide.
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+const treeSuffix = "-tree"
+
+// TreeAlgorithm returns the algorithm name of a Merkle tree root of algo over
+// chunkSize byte chunks, for example "sha256-tree-1048576". A chunk size of zero
+// names a tree over chunks of any size, "sha256-tree".
+//
+// The root is not the digest of the data under algo, so it never carries algo's
+// own name: ParseHashSum, Equal and Verify keep the two apart.
+func TreeAlgorithm[T AnyHashAlgorithm](algo T, chunkSize int) string {
+	if chunkSize <= 0 {
+		return string(algo) + treeSuffix
+	}
+	return string(algo) + treeSuffix + "-" + strconv.Itoa(chunkSize)
+}
+
+// ParseTreeAlgorithm splits a name returned by TreeAlgorithm into the algorithm
+// and the chunk size, which is zero if the name has none.
+func ParseTreeAlgorithm(name string) (algo string, chunkSize int, ok bool) {
+	if algo, ok := strings.CutSuffix(name, treeSuffix); ok {
+		return algo, 0, algo != ""
+	}
+
+	i := strings.LastIndex(name, treeSuffix+"-")
+	if i <= 0 {
+		return "", 0, false
+	}
+	size := name[i+len(treeSuffix)+1:]
+	chunkSize, err := strconv.Atoi(size)
+	if err != nil || chunkSize <= 0 || strconv.Itoa(chunkSize) != size {
+		return "", 0, false
+	}
+	return name[:i], chunkSize, true
+}
+
+// HashReaderParallel hashes r using several goroutines.
+//
+// BLAKE3 (Blake3 and Blake3XOF) is split along its native tree, so the result is
+// identical to HashReader. Every other algorithm hashes ChunkSize pieces as
+// leaves of an RFC 6962 style Merkle tree: leaves are H(0x00 || chunk), inner
+// nodes H(0x01 || left || right). That root is not the plain digest of r and
+// depends on ChunkSize, so the sum is named TreeAlgorithm(algo, ChunkSize) and
+// only compares equal to roots computed with the same algorithm and chunk size.
+// Extendable-output algorithms other than Blake3XOF return ErrUnsupported.
+func HashReaderParallel[T AnyHashAlgorithm](algo T, r io.Reader, opts ParallelOptions, key ...[]byte) (*HashSum, error) {
+	p, err := newParallelHash(string(algo), opts, false, key...)
+	if err != nil {
+		return nil, err
+	}
+
+	units, err := hashUnitsReader(r, p.opts, p.unit)
+	if err != nil {
+		return nil, err
+	}
+	return p.finish(units)
+}
+
+// HashReaderAtParallel is HashReaderParallel for the first size bytes of r.
+// Workers read their own chunks with ReadAt, so the source is read concurrently.
+func HashReaderAtParallel[T AnyHashAlgorithm](algo T, r io.ReaderAt, size int64, opts ParallelOptions, key ...[]byte) (*HashSum, error) {
+	p, err := newParallelHash(string(algo), opts, false, key...)
+	if err != nil {
+		return nil, err
+	}
+
+	units, err := hashUnitsReaderAt(r, size, p.opts, p.unit)
+	if err != nil {
+		return nil, err
+	}
+	return p.finish(units)
+}
+
+type parallelHash struct {
+	algo string
+	opts ParallelOptions
+	fn   func() hash.Hash
+
+	blake3 bool
+	tree   b3Tree
+	size   int
+}
+
+// hashedUnit is the result for one ChunkSize piece of the input.
+type hashedUnit struct {
+	digest []byte
+	cv     [8]uint32
+	// data is kept only while the whole input may fit in a single unit.
+	data []byte
+	n    int
+}
+
+// newParallelHash prepares a parallel hash of algo. Unless merkle is set, BLAKE3 uses
+// its native tree rather than the Merkle tree.
+func newParallelHash(algo string, opts ParallelOptions, merkle bool, key ...[]byte) (*parallelHash, error) {
+	fn, err := GetHash(algo, key...)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parallelHash{
+		algo: algo,
+		opts: opts.withDefaults(),
+		fn:   fn,
+	}
+
+	if !merkle {
+		switch normalizeAlgName[HashAlgorithm](algo) {
+		case HashAlgorithm(Blake3):
+			p.blake3, p.tree, p.size = true, newB3Tree(key[0]), fn().Size()
+		case HashAlgorithm(Blake3XOF):
+			p.blake3, p.tree, p.size = true, newB3Tree(nil), fn().Size()
+		}
+	}
+
+	if p.blake3 {
+		chunk := uint64(max(p.opts.ChunkSize, guts.ChunkSize))
+		p.opts.ChunkSize = int(1 << (64 - bits.LeadingZeros64(chunk-1)))
+		return p, nil
+	}
+
+	// A Merkle tree needs a fixed size digest, which other XOFs do not have.
+	if class, _ := ClassOf(algo); class == ClassXOF || class == ClassKDF {
+		return nil, ErrUnsupported
+	}
+	return p, nil
+}
+
+func (p *parallelHash) unit(index int, data []byte) hashedUnit {
+	u := hashedUnit{n: len(data)}
+
+	if p.blake3 {
+		if index == 0 {
+			u.data = append([]byte(nil), data...)
+		}
+		counter := uint64(index) * uint64(p.opts.ChunkSize/guts.ChunkSize)
+		u.cv = guts.ChainingValue(p.tree.subtree(data, counter))
+		return u
+	}
+
+	h := p.fn()
+	h.Write([]byte{leafPrefix})
+	h.Write(data)
+	u.digest = h.Sum(nil)
+	return u
+}
+
+func (p *parallelHash) finish(units []hashedUnit) (*HashSum, error) {
+	var digest []byte
+
+	switch {
+	case len(units) == 0:
+		// Both BLAKE3 and the Merkle tree define the empty input as the hash of nothing.
+		digest = p.fn().Sum(nil)
+	case p.blake3 && len(units) == 1:
+		// A single unit is the root itself, which needs the ROOT flag rather than a chaining value.
+		h := p.fn()
+		h.Write(units[0].data)
+		digest = h.Sum(nil)
+	case p.blake3:
+		cvs := make([][8]uint32, len(units))
+		var total int
+		for i, u := range units {
+			cvs[i] = u.cv
+			total += u.n
+		}
+		chunks := uint64(total+guts.ChunkSize-1) / guts.ChunkSize
+		root := p.tree.join(cvs, uint64(p.opts.ChunkSize/guts.ChunkSize), 0, chunks)
+		digest = p.tree.rootBytes(root, p.size)
+	default:
+		leaves := make([][]byte, len(units))
+		for i, u := range units {
+			leaves[i] = u.digest
+		}
+		digest = merkleRoot(p.fn, leaves)
+	}
+
+	algo := p.algo
+	if !p.blake3 {
+		algo = TreeAlgorithm(algo, p.opts.ChunkSize)
+	}
+	return newHashSum(algo, digest)
+}
+
+// hashReaderTree recomputes the root of a sum named by TreeAlgorithm, for any
+// algorithm including BLAKE3.
+func hashReaderTree(name string, r io.Reader, key ...[]byte) (*HashSum, error) {
+	algo, chunkSize, ok := ParseTreeAlgorithm(name)
+	if !ok || chunkSize == 0 {
+		return nil, ErrUnsupported
+	}
+	p, err := newParallelHash(algo, ParallelOptions{ChunkSize: chunkSize}, true, key...)
+	if err != nil {
+		return nil, err
+	}
+
+	units, err := hashUnitsReader(r, p.opts, p.unit)
+	if err != nil {
+		return nil, err
+	}
+	sum, err := p.finish(units)
+	if err != nil {
+		return nil, err
+	}
+	sum.algo = name
+	return sum, nil
+}
+
+func merkleRoot(fn func() hash.Hash, leaves [][]byte) []byte {
+	if len(leaves) == 1 {
+		return leaves[0]
+	}
+	k := largestPowerOfTwoBelow(uint64(len(leaves)))
+	h := fn()
+	h.Write([]byte{nodePrefix})
+	h.Write(merkleRoot(fn, leaves[:k]))
+	h.Write(merkleRoot(fn, leaves[k:]))
+	return h.Sum(nil)
+}
+
+func hashUnitsReaderAt(r io.ReaderAt, size int64, opts ParallelOptions, fn func(int, []byte) hashedUnit) ([]hashedUnit, error) {
+	if size < 0 {
+		return nil, errors.New("hashx: negative size")
+	}
+
+	chunk := int64(opts.ChunkSize)
+	units := make([]hashedUnit, (size+chunk-1)/chunk)
+
+	var (
+		wg       sync.WaitGroup
+		next     = make(chan int)
+		errOnce  sync.Once
+		firstErr error
+	)
+
+	for w := 0; w < min(opts.Workers, len(units)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buf := make([]byte, chunk)
+			for i := range next {
+				off := int64(i) * chunk
+				n := min(chunk, size-off)
+				read, err := r.ReadAt(buf[:n], off)
+				if int64(read) < n {
+					if err == nil || err == io.EOF {
+						err = io.ErrUnexpectedEOF
+					}
+					errOnce.Do(func() { firstErr = err })
+					continue
+				}
+				units[i] = fn(i, buf[:n])
+			}
+		}()
+	}
+
+	for i := range units {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return units, nil
+}
+
+func hashUnitsReader(r io.Reader, opts ParallelOptions, fn func(int, []byte) hashedUnit) ([]hashedUnit, error) {
+	type job struct {
+		index int
+		buf   *bytes.Buffer
+	}
+
+	var (
+		mu    sync.Mutex
+		units []hashedUnit
+		wg    sync.WaitGroup
+		jobs  = make(chan job, opts.Workers)
+		pool  = sync.Pool{New: func() any { return bytes.NewBuffer(make([]byte, 0, opts.ChunkSize)) }}
+	)
+
+	for w := 0; w < opts.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				u := fn(j.index, j.buf.Bytes())
+				pool.Put(j.buf)
+
+				mu.Lock()
+				units[j.index] = u
+				mu.Unlock()
+			}
+		}()
+	}
+
+	var readErr error
+	for i := 0; ; i++ {
+		buf := pool.Get().(*bytes.Buffer)
+		buf.Reset()
+		n, err := io.CopyN(buf, r, int64(opts.ChunkSize))
+		if n > 0 {
+			mu.Lock()
+			units = append(units, hashedUnit{})
+			mu.Unlock()
+			jobs <- job{index: i, buf: buf}
+		}
+		if err != nil {
+			if err != io.EOF {
+				readErr = err
+			}
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if readErr != nil {
+		return nil, readErr
+	}
+	return units, nil
+}
@@ -0,0 +1,161 @@
+package hashx
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"strings"
+	"testing"
+)
+
+func TestParallelBlake3(t *testing.T) {
+	key := make([]byte, 32)
+	rand.Read(key)
+
+	data := make([]byte, 64*1024+123)
+	rand.Read(data)
+
+	sizes := []int{0, 1, 1023, 1024, 1025, 2048, 3*1024 + 7, 9 * 1024, 33*1024 + 1, len(data)}
+	opts := []ParallelOptions{
+		{ChunkSize: 1024, Workers: 3},
+		{ChunkSize: 3000, Workers: 2},
+		{ChunkSize: 8 * 1024},
+	}
+
+	for _, size := range sizes {
+		for _, o := range opts {
+			want, err := HashBytes(Blake3, data[:size], key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := HashReaderParallel(Blake3, bytes.NewReader(data[:size]), o, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Encode() != want.Encode() {
+				t.Fatalf("size %d, chunk %d: expected %s, got %s", size, o.ChunkSize, want.Encode(), got.Encode())
+			}
+
+			wantXOF, _ := HashBytes(Blake3XOF, data[:size])
+			gotXOF, err := HashReaderAtParallel(Blake3XOF, bytes.NewReader(data[:size]), int64(size), o)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gotXOF.Encode() != wantXOF.Encode() {
+				t.Fatalf("size %d, chunk %d: expected %s, got %s", size, o.ChunkSize, wantXOF.Encode(), gotXOF.Encode())
+			}
+		}
+	}
+}
+
+func TestParallelMerkle(t *testing.T) {
+	leaf := func(b []byte) []byte {
+		s := sha256.Sum256(append([]byte{leafPrefix}, b...))
+		return s[:]
+	}
+	node := func(l, r []byte) []byte {
+		s := sha256.Sum256(append(append([]byte{nodePrefix}, l...), r...))
+		return s[:]
+	}
+
+	data := bytes.Repeat([]byte("0123456789"), 30)
+	// Three 100 byte leaves: the left subtree takes two, the right one.
+	want := node(node(leaf(data[:100]), leaf(data[100:200])), leaf(data[200:]))
+
+	o := ParallelOptions{ChunkSize: 100, Workers: 4}
+	got, err := HashReaderParallel(SHA256, bytes.NewReader(data), o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.data, want) {
+		t.Fatalf("unexpected root %x", got.data)
+	}
+
+	gotAt, err := HashReaderAtParallel(SHA256, bytes.NewReader(data), int64(len(data)), o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(gotAt.data, want) {
+		t.Fatalf("unexpected root %x", gotAt.data)
+	}
+
+	if _, err := HashReaderAtParallel(SHA256, bytes.NewReader(data), int64(len(data))+1, o); err == nil {
+		t.Fatal("expected an error reading past the end")
+	}
+}
+
+// A Merkle root is not the digest of the data, so it must not pass for one.
+func TestParallelTreeAlgorithm(t *testing.T) {
+	sum, err := HashReaderParallel(SHA256, strings.NewReader("abc"), ParallelOptions{ChunkSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum.Algorithm() != "sha256-tree-2" {
+		t.Fatalf("unexpected algorithm %s", sum.Algorithm())
+	}
+
+	plain, _ := HashString(SHA256, "abc")
+	if sum.Equal(plain) || bytes.Equal(sum.Bytes(), plain.Bytes()) {
+		t.Fatal("tree root equals the plain digest")
+	}
+	if err := plain.VerifyBytes(sum.Bytes()); err == nil {
+		t.Fatal("tree root verified as a plain digest")
+	}
+
+	parsed, err := ParseHashSum(sum.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !parsed.Equal(sum) {
+		t.Fatalf("round trip changed %s to %s", sum.Encode(), parsed.Encode())
+	}
+	if err := parsed.VerifyString("abc"); err != nil {
+		t.Fatal(err)
+	}
+	if err := parsed.VerifyString("abd"); err != ErrMismatch {
+		t.Fatalf("expected ErrMismatch, got %v", err)
+	}
+
+	for _, algo := range []XOFAlgorithm{SHAKE128, SHAKE256} {
+		if _, err := HashReaderParallel(algo, strings.NewReader("abc"), ParallelOptions{ChunkSize: 2}); err != ErrUnsupported {
+			t.Fatalf("%s: expected ErrUnsupported, got %v", algo, err)
+		}
+	}
+	var text HashSum
+	if err := text.UnmarshalText([]byte(sum.Encode())); err != nil || !text.Equal(sum) {
+		t.Fatalf("UnmarshalText(%s) = %s, %v", sum.Encode(), text.Encode(), err)
+	}
+
+	if tree, err := NewHashSum(TreeAlgorithm(SHAKE128, 1024), sum.Bytes()); err == nil {
+		t.Fatalf("accepted a tree over an XOF: %s", tree.Encode())
+	}
+
+	for _, name := range []string{"sha256-tree-0", "sha256-tree-01", "sha256-tree-x", "-tree-5", "-tree", "sha256-tree5"} {
+		if _, _, ok := ParseTreeAlgorithm(name); ok {
+			t.Errorf("parsed %q as a tree algorithm", name)
+		}
+	}
+	if algo, size, ok := ParseTreeAlgorithm("sha3-256-tree"); !ok || algo != "sha3-256" || size != 0 {
+		t.Fatalf("ParseTreeAlgorithm = %s, %d, %v", algo, size, ok)
+	}
+}
+
+func BenchmarkParallelBlake3(b *testing.B) {
+	data := make([]byte, 64<<20)
+	rand.Read(data)
+	key := make([]byte, 32)
+
+	b.Run("sequential", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			HashReader(Blake3, bytes.NewReader(data), key)
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			HashReaderAtParallel(Blake3, bytes.NewReader(data), int64(len(data)), ParallelOptions{}, key)
+		}
+	})
+}
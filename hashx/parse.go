@@ -0,0 +1,112 @@
+package hashx
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"strings"
+	"unsafe"
+)
+
+// The Parse functions validate an algorithm name read from configuration or user
+// input. Names are matched case insensitively, with '_' and '-' interchangeable,
+// and surrounding whitespace is ignored. The returned value is the canonical name,
+// so its methods never fail for want of a registered algorithm.
+//
+// An unknown name gives an *UnknownAlgorithmError and a name of the wrong kind,
+// such as "sha256" for ParseHash64Algorithm, an error matching ErrUnsupported.
+
+// ParseHashAlgorithm returns the canonical name of an unkeyed algorithm.
+func ParseHashAlgorithm(name string) (HashAlgorithm, error) {
+	return parseAlgorithm[HashAlgorithm](name, ClassPlain)
+}
+
+// ParseKeyedHashAlgorithm returns the canonical name of a keyed algorithm.
+func ParseKeyedHashAlgorithm(name string) (KeyedHashAlgorithm, error) {
+	return parseAlgorithm[KeyedHashAlgorithm](name, ClassKeyed)
+}
+
+// ParseHash32Algorithm returns the canonical name of a 32-bit algorithm.
+func ParseHash32Algorithm(name string) (Hash32Algorithm, error) {
+	return parseAlgorithm[Hash32Algorithm](name, ClassHash32)
+}
+
+// ParseHash64Algorithm returns the canonical name of a 64-bit algorithm.
+func ParseHash64Algorithm(name string) (Hash64Algorithm, error) {
+	return parseAlgorithm[Hash64Algorithm](name, ClassHash64)
+}
+
+// ParseHash128Algorithm returns the canonical name of a 128-bit algorithm.
+func ParseHash128Algorithm(name string) (Hash128Algorithm, error) {
+	return parseAlgorithm[Hash128Algorithm](name, ClassHash128)
+}
+
+// ParseXOFAlgorithm returns the canonical name of an extendable-output algorithm.
+func ParseXOFAlgorithm(name string) (XOFAlgorithm, error) {
+	return parseAlgorithm[XOFAlgorithm](name, ClassXOF)
+}
+
+// ParseKDFAlgorithm returns the canonical name of a key derivation algorithm.
+func ParseKDFAlgorithm(name string) (KDFAlgorithm, error) {
+	return parseAlgorithm[KDFAlgorithm](name, ClassKDF)
+}
+
+func parseAlgorithm[T AnyHashAlgorithm](name string, class Class) (T, error) {
+	algo := normalizeAlgName[T](strings.TrimSpace(name))
+	entry, ok := lookup(string(algo))
+	if !ok {
+		return "", &UnknownAlgorithmError{Algorithm: name}
+	}
+	if entry.class != class {
+		return "", fmt.Errorf("%w: %q is a %s algorithm, not %s", ErrUnsupported, name, entry.class, class)
+	}
+	return algo, nil
+}
+
+// lookupClass finds a registered algorithm of the given class.
+func lookupClass(name string, class Class) (algorithmEntry, error) {
+	entry, ok := lookup(name)
+	if !ok || entry.class != class {
+		return algorithmEntry{}, ErrUnsupported
+	}
+	return entry, nil
+}
+
+// stringBytes returns the bytes of s without copying them. hash.Hash writers
+// must neither modify nor retain their input, so they can be fed the result.
+func stringBytes(s string) []byte {
+	return unsafe.Slice(unsafe.StringData(s), len(s))
+}
+
+func hashValue32(h hash.Hash) uint32 {
+	if h32, ok := h.(hash.Hash32); ok {
+		return h32.Sum32()
+	}
+	return binary.BigEndian.Uint32(h.Sum(nil))
+}
+
+func hashValue64(h hash.Hash) uint64 {
+	if h64, ok := h.(hash.Hash64); ok {
+		return h64.Sum64()
+	}
+	return binary.BigEndian.Uint64(h.Sum(nil))
+}
+
+// fnv32a and fnv64a are one-shot FNV-1a, equal to hash/fnv without the allocation.
+func fnv32a(p []byte) uint32 {
+	h := uint32(2166136261)
+	for _, c := range p {
+		h ^= uint32(c)
+		h *= 16777619
+	}
+	return h
+}
+
+func fnv64a(p []byte) uint64 {
+	h := uint64(14695981039346656037)
+	for _, c := range p {
+		h ^= uint64(c)
+		h *= 1099511628211
+	}
+	return h
+}
@@ -0,0 +1,151 @@
+package hashx
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseAlgorithm(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		want Hash64Algorithm
+	}{
+		{"xxh64", XXH64},
+		{"XXH3_64", XXH3_64},
+		{" crc64-NVME\n", CRC64_NVME},
+		{"Fnv64", FNV64},
+	} {
+		got, err := ParseHash64Algorithm(c.name)
+		if err != nil {
+			t.Fatalf("ParseHash64Algorithm(%q): %v", c.name, err)
+		}
+		if got != c.want {
+			t.Fatalf("ParseHash64Algorithm(%q) = %q, expected %q", c.name, got, c.want)
+		}
+	}
+
+	var unknown *UnknownAlgorithmError
+	if _, err := ParseHash64Algorithm("xxh65"); !errors.As(err, &unknown) || !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("expected an UnknownAlgorithmError, got %v", err)
+	}
+	if _, err := ParseHash64Algorithm("sha256"); !errors.Is(err, ErrUnsupported) || errors.As(err, &unknown) {
+		t.Fatalf("expected a class error, got %v", err)
+	}
+	if _, err := ParseHash32Algorithm("crc64"); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+
+	if algo, err := ParseHash32Algorithm("CRC32C"); err != nil || algo != CRC32C {
+		t.Fatalf("ParseHash32Algorithm = %q, %v", algo, err)
+	}
+	if algo, err := ParseHash128Algorithm("xxh3_128"); err != nil || algo != XXH3_128 {
+		t.Fatalf("ParseHash128Algorithm = %q, %v", algo, err)
+	}
+	if algo, err := ParseHashAlgorithm("SHA3_256"); err != nil || algo != SHA3_256 {
+		t.Fatalf("ParseHashAlgorithm = %q, %v", algo, err)
+	}
+	if algo, err := ParseKeyedHashAlgorithm("HMAC-SHA256"); err != nil || algo != HMAC_SHA256 {
+		t.Fatalf("ParseKeyedHashAlgorithm = %q, %v", algo, err)
+	}
+	if algo, err := ParseKDFAlgorithm("argon2id"); err != nil || algo != Argon2id {
+		t.Fatalf("ParseKDFAlgorithm = %q, %v", algo, err)
+	}
+}
+
+func TestUnsupportedDoesNotPanic(t *testing.T) {
+	bogus32 := Hash32Algorithm("nope")
+	bogus64 := Hash64Algorithm("sha256")
+	bogus128 := Hash128Algorithm("")
+
+	if v := bogus32.HashBytes([]byte("a")); v != 0 {
+		t.Fatalf("expected 0, got %d", v)
+	}
+	if v := bogus64.HashString("a"); v != 0 {
+		t.Fatalf("expected 0, got %d", v)
+	}
+	if v := bogus128.HashString("a"); v != (Uint128{}) {
+		t.Fatalf("expected zero, got %v", v)
+	}
+
+	if _, err := bogus32.Sum32([]byte("a")); err != ErrUnsupported {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+	if _, err := bogus64.Sum64String("a"); err != ErrUnsupported {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+	if _, err := bogus64.HashReader(strings.NewReader("a")); err != ErrUnsupported {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+	if _, err := bogus128.Sum128([]byte("a")); err != ErrUnsupported {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+	if _, err := bogus64.Bucket([]byte("a"), 10); err != ErrUnsupported {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+}
+
+// The one-shot functions must agree with the streaming hashers.
+func TestOneShotMatchesHasher(t *testing.T) {
+	inputs := []string{"", "a", "123456789", strings.Repeat("belt", 1000)}
+
+	for _, name := range AlgorithmsOf(ClassHash32) {
+		algo := Hash32Algorithm(name)
+		for _, in := range inputs {
+			got, err := algo.Sum32String(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := algo.HashReader(strings.NewReader(in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Fatalf("%s(%.10q) = %#x, hasher gives %#x", name, in, got, want)
+			}
+		}
+	}
+
+	for _, name := range AlgorithmsOf(ClassHash64) {
+		algo := Hash64Algorithm(name)
+		for _, in := range inputs {
+			got, err := algo.Sum64String(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := algo.HashReader(strings.NewReader(in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Fatalf("%s(%.10q) = %#x, hasher gives %#x", name, in, got, want)
+			}
+		}
+	}
+}
+
+func TestHashStringAllocs(t *testing.T) {
+	s := strings.Repeat("x", 100)
+	for _, algo := range []Hash32Algorithm{CRC32, CRC32C, FNV32, Murmur3_32} {
+		if n := testing.AllocsPerRun(100, func() { algo.HashString(s) }); n != 0 {
+			t.Errorf("%s: %v allocations", algo, n)
+		}
+	}
+	for _, algo := range []Hash64Algorithm{CRC64, CRC64_ECMA, CRC64_NVME, FNV64, XXH64, XXH3_64, WyHash} {
+		if n := testing.AllocsPerRun(100, func() { algo.HashString(s) }); n != 0 {
+			t.Errorf("%s: %v allocations", algo, n)
+		}
+	}
+}
+
+func BenchmarkHashString(b *testing.B) {
+	s := strings.Repeat("x", 64)
+	for _, algo := range []Hash64Algorithm{FNV64, XXH64, XXH3_64} {
+		b.Run(string(algo), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				algo.HashString(s)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package hashx
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+var (
+	_ Placement = (*Ring)(nil)
+	_ Placement = (*Rendezvous)(nil)
+	_ Placement = (*MultiProbe)(nil)
+)
+
+func newPlacements(t *testing.T, algo Hash64Algorithm) map[string]Placement {
+	ring, err := NewRing(algo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hrw, err := NewRendezvous(algo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mp, err := NewMultiProbe(algo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]Placement{"ring": ring, "rendezvous": hrw, "multiprobe": mp}
+}
+
+func TestPlacementDistribution(t *testing.T) {
+	const keys = 50000
+
+	for _, algo := range []Hash64Algorithm{FNV64, CRC64} {
+		for name, p := range newPlacements(t, algo) {
+			t.Run(fmt.Sprintf("%s/%s", algo, name), func(t *testing.T) {
+				for i := 0; i < 4; i++ {
+					p.Add(fmt.Sprintf("node-%d", i), 1)
+				}
+				p.Add("node-big", 2)
+
+				counts := make(map[string]int)
+				for i := 0; i < keys; i++ {
+					m, _ := p.Get([]byte(fmt.Sprintf("object-%d", i)))
+					counts[m]++
+				}
+
+				// Total weight is 6: one sixth per unit of weight.
+				for m, c := range counts {
+					want := float64(keys) / 6
+					if m == "node-big" {
+						want *= 2
+					}
+					if math.Abs(float64(c)-want)/want > 0.25 {
+						t.Fatalf("%s got %d keys, expected about %.0f", m, c, want)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestPlacementStability(t *testing.T) {
+	for name, p := range newPlacements(t, FNV64) {
+		t.Run(name, func(t *testing.T) {
+			for i := 0; i < 5; i++ {
+				p.Add(fmt.Sprintf("node-%d", i), 1)
+			}
+
+			before := make(map[string]string)
+			for i := 0; i < 10000; i++ {
+				k := fmt.Sprintf("object-%d", i)
+				before[k], _ = p.Get([]byte(k))
+			}
+
+			p.Remove("node-2")
+			for k, owner := range before {
+				now, _ := p.Get([]byte(k))
+				if owner != "node-2" && now != owner {
+					t.Fatalf("%s moved from %s to %s although its owner stayed", k, owner, now)
+				}
+				if now == "node-2" {
+					t.Fatalf("%s still maps to a removed member", k)
+				}
+			}
+		})
+	}
+}
+
+func TestPlacementReplicas(t *testing.T) {
+	for name, p := range newPlacements(t, FNV64) {
+		t.Run(name, func(t *testing.T) {
+			if _, ok := p.Get([]byte("x")); ok {
+				t.Fatal("expected no owner for an empty placement")
+			}
+			if err := p.Add("a", 0); err != ErrInvalidWeight {
+				t.Fatalf("expected ErrInvalidWeight, got %v", err)
+			}
+
+			p.Add("a", 1)
+			p.Add("b", 1)
+			p.Add("c", 1)
+
+			replicas := p.GetN([]byte("object"), 5)
+			if len(replicas) != 3 {
+				t.Fatalf("expected 3 distinct replicas, got %v", replicas)
+			}
+			seen := make(map[string]bool)
+			for _, m := range replicas {
+				if seen[m] {
+					t.Fatalf("duplicate replica in %v", replicas)
+				}
+				seen[m] = true
+			}
+
+			first, _ := p.Get([]byte("object"))
+			if replicas[0] != first {
+				t.Fatalf("first replica %s differs from owner %s", replicas[0], first)
+			}
+		})
+	}
+
+	if _, err := NewRing(Hash64Algorithm("sha256")); err != ErrUnsupported {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+}
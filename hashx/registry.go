@@ -0,0 +1,214 @@
+package hashx
+
+import (
+	"fmt"
+	"hash"
+	"sort"
+	"sync"
+)
+
+// Class is the kind of algorithm a registered name belongs to.
+type Class int
+
+const (
+	// ClassPlain is an unkeyed HashAlgorithm.
+	ClassPlain Class = iota + 1
+	// ClassKeyed is a KeyedHashAlgorithm.
+	ClassKeyed
+	// ClassHash32 is a Hash32Algorithm.
+	ClassHash32
+	// ClassHash64 is a Hash64Algorithm.
+	ClassHash64
+	// ClassXOF is an extendable-output XOFAlgorithm.
+	ClassXOF
+	// ClassKDF is a password hashing or key derivation KDFAlgorithm.
+	ClassKDF
+	// ClassHash128 is a Hash128Algorithm.
+	ClassHash128
+)
+
+func (c Class) String() string {
+	switch c {
+	case ClassPlain:
+		return "plain"
+	case ClassKeyed:
+		return "keyed"
+	case ClassHash32:
+		return "hash32"
+	case ClassHash64:
+		return "hash64"
+	case ClassXOF:
+		return "xof"
+	case ClassKDF:
+		return "kdf"
+	case ClassHash128:
+		return "hash128"
+	default:
+		return "unknown"
+	}
+}
+
+// KeyedHashFunc validates key and returns a constructor for hashes keyed with it.
+type KeyedHashFunc func(key []byte) (func() hash.Hash, error)
+
+// AlgorithmInfo describes a registered algorithm.
+type AlgorithmInfo struct {
+	Name  string
+	Class Class
+}
+
+type algorithmEntry struct {
+	class    Class
+	newHash  func() hash.Hash
+	newKeyed KeyedHashFunc
+	newXOF   func() XOF
+	kdf      kdfFunc
+	// newSeeded is set for algorithms that take a seed, see RegisterSeeded.
+	newSeeded func(seed uint64) hash.Hash
+	// sum32 and sum64 are optional one-shot functions that hash without
+	// allocating a hasher, see setSum32 and setSum64.
+	sum32 func([]byte) uint32
+	sum64 func([]byte) uint64
+}
+
+var (
+	algorithmRegistry = make(map[string]algorithmEntry)
+	registryMu        sync.RWMutex
+)
+
+// Register adds an unkeyed algorithm. It panics if the name is already registered.
+func Register(algo HashAlgorithm, fn func() hash.Hash) {
+	register(string(algo), algorithmEntry{class: ClassPlain, newHash: fn})
+}
+
+// RegisterKeyed adds a keyed algorithm. It panics if the name is already registered.
+func RegisterKeyed(algo KeyedHashAlgorithm, fn KeyedHashFunc) {
+	register(string(algo), algorithmEntry{class: ClassKeyed, newKeyed: fn})
+}
+
+// Register32 adds a 32-bit algorithm. It panics if the name is already registered.
+func Register32(algo Hash32Algorithm, fn func() hash.Hash32) {
+	register(string(algo), algorithmEntry{class: ClassHash32, newHash: func() hash.Hash {
+		return fn()
+	}})
+}
+
+// Register64 adds a 64-bit algorithm. It panics if the name is already registered.
+func Register64(algo Hash64Algorithm, fn func() hash.Hash64) {
+	register(string(algo), algorithmEntry{class: ClassHash64, newHash: func() hash.Hash {
+		return fn()
+	}})
+}
+
+// Register128 adds a 128-bit algorithm. fn must return hashes with a 16 byte digest.
+// It panics if the name is already registered.
+func Register128(algo Hash128Algorithm, fn func() hash.Hash) {
+	register(string(algo), algorithmEntry{class: ClassHash128, newHash: fn})
+}
+
+// RegisterSeeded adds a seeded 32, 64 or 128-bit algorithm, depending on the type
+// of algo. The unseeded hash is the one with seed 0. Algorithms with a 32-bit seed
+// use the low 32 bits. It panics if the name is already registered.
+func RegisterSeeded[T Hash32Algorithm | Hash64Algorithm | Hash128Algorithm](algo T, fn func(seed uint64) hash.Hash) {
+	entry := algorithmEntry{
+		newHash:   func() hash.Hash { return fn(0) },
+		newSeeded: fn,
+	}
+	switch any(algo).(type) {
+	case Hash32Algorithm:
+		entry.class = ClassHash32
+	case Hash64Algorithm:
+		entry.class = ClassHash64
+	case Hash128Algorithm:
+		entry.class = ClassHash128
+	}
+	register(string(algo), entry)
+}
+
+func register(name string, entry algorithmEntry) {
+	if entry.newHash == nil && entry.newKeyed == nil && entry.kdf == nil {
+		panic(fmt.Sprintf("hashx: nil constructor for algorithm %q", name))
+	}
+
+	name = string(normalizeAlgName[HashAlgorithm](name))
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, exists := algorithmRegistry[name]; exists {
+		panic(fmt.Sprintf("hashx: algorithm %q already registered", name))
+	}
+	algorithmRegistry[name] = entry
+}
+
+// setSum32 attaches a one-shot function to an already registered 32-bit algorithm.
+// It must agree with the algorithm's hasher.
+func setSum32(algo Hash32Algorithm, fn func([]byte) uint32) {
+	updateEntry(string(algo), func(entry *algorithmEntry) { entry.sum32 = fn })
+}
+
+// setSum64 attaches a one-shot function to an already registered 64-bit algorithm.
+// It must agree with the algorithm's hasher.
+func setSum64(algo Hash64Algorithm, fn func([]byte) uint64) {
+	updateEntry(string(algo), func(entry *algorithmEntry) { entry.sum64 = fn })
+}
+
+func updateEntry(name string, update func(*algorithmEntry)) {
+	name = string(normalizeAlgName[HashAlgorithm](name))
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	entry, ok := algorithmRegistry[name]
+	if !ok {
+		panic(fmt.Sprintf("hashx: algorithm %q is not registered", name))
+	}
+	update(&entry)
+	algorithmRegistry[name] = entry
+}
+
+func lookup(name string) (algorithmEntry, bool) {
+	registryMu.RLock()
+	entry, ok := algorithmRegistry[string(normalizeAlgName[HashAlgorithm](name))]
+	registryMu.RUnlock()
+	return entry, ok
+}
+
+// ClassOf reports the class of a registered algorithm.
+func ClassOf[T AnyHashAlgorithm](algo T) (Class, bool) {
+	entry, ok := lookup(string(algo))
+	if !ok {
+		return 0, false
+	}
+	return entry.class, true
+}
+
+// IsRegistered reports whether algo has been registered.
+func IsRegistered[T AnyHashAlgorithm](algo T) bool {
+	_, ok := lookup(string(algo))
+	return ok
+}
+
+// Algorithms lists every registered algorithm sorted by name.
+func Algorithms() []AlgorithmInfo {
+	registryMu.RLock()
+	infos := make([]AlgorithmInfo, 0, len(algorithmRegistry))
+	for name, entry := range algorithmRegistry {
+		infos = append(infos, AlgorithmInfo{Name: name, Class: entry.class})
+	}
+	registryMu.RUnlock()
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
+}
+
+// AlgorithmsOf lists the registered algorithms of a single class sorted by name.
+func AlgorithmsOf(class Class) []string {
+	var names []string
+	for _, info := range Algorithms() {
+		if info.Class == class {
+			names = append(names, info.Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,66 @@
+package hashx
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha512"
+	"hash"
+	"hash/adler32"
+	"testing"
+)
+
+func TestRegister(t *testing.T) {
+	const sha512_256 HashAlgorithm = "test-sha512-256"
+	const hmacSha512_256 KeyedHashAlgorithm = "test-hmac-sha512-256"
+	const adler Hash32Algorithm = "test-adler32"
+
+	Register(sha512_256, sha512.New512_256)
+	RegisterKeyed(hmacSha512_256, func(key []byte) (func() hash.Hash, error) {
+		return func() hash.Hash {
+			return hmac.New(sha512.New512_256, key)
+		}, nil
+	})
+	Register32(adler, func() hash.Hash32 { return adler32.New() })
+
+	sum, err := HashString(sha512_256, "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := sha512.Sum512_256([]byte("hello"))
+	if !bytes.Equal(sum.data, want[:]) {
+		t.Fatalf("unexpected digest %x", sum.data)
+	}
+
+	if !IsKeyed(hmacSha512_256) || IsKeyed(sha512_256) {
+		t.Fatal("IsKeyed reported the wrong class")
+	}
+	if _, err := HashString(hmacSha512_256, "hello"); err != ErrKeyRequired {
+		t.Fatalf("expected ErrKeyRequired, got %v", err)
+	}
+
+	if got, want := adler.HashString("hello"), adler32.Checksum([]byte("hello")); got != want {
+		t.Fatalf("expected %d, got %d", want, got)
+	}
+
+	class, ok := ClassOf(HashAlgorithm("TEST_SHA512_256"))
+	if !ok || class != ClassPlain {
+		t.Fatalf("expected normalized lookup to find %s", sha512_256)
+	}
+
+	found := false
+	for _, info := range Algorithms() {
+		if info.Name == string(adler) && info.Class == ClassHash32 {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("%s missing from Algorithms()", adler)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected duplicate registration to panic")
+		}
+	}()
+	Register(SHA256, sha512.New512_256)
+}
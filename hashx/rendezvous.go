@@ -0,0 +1,111 @@
+package hashx
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/atlastore/belt/hashx/internal/mix"
+)
+
+// Rendezvous implements weighted rendezvous (highest random weight) hashing.
+// Every member scores every key and the highest scores win, so removing a member
+// only moves the keys it owned and adding one only takes keys it now scores highest on.
+// It is safe for concurrent use.
+type Rendezvous struct {
+	mu      sync.RWMutex
+	algo    Hash64Algorithm
+	weights map[string]int
+}
+
+// NewRendezvous creates an empty rendezvous hasher hashed with algo.
+func NewRendezvous(algo Hash64Algorithm) (*Rendezvous, error) {
+	if _, err := getHash64Func(algo); err != nil {
+		return nil, err
+	}
+	return &Rendezvous{
+		algo:    algo,
+		weights: make(map[string]int),
+	}, nil
+}
+
+func (r *Rendezvous) Add(member string, weight int) error {
+	if weight <= 0 {
+		return ErrInvalidWeight
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.weights[member] = weight
+	return nil
+}
+
+func (r *Rendezvous) Remove(member string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.weights, member)
+}
+
+func (r *Rendezvous) Members() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return sortedMembers(r.weights)
+}
+
+func (r *Rendezvous) Get(key []byte) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	best, bestScore := "", math.Inf(-1)
+	for member, weight := range r.weights {
+		score := r.score(member, weight, key)
+		if score > bestScore || (score == bestScore && member < best) {
+			best, bestScore = member, score
+		}
+	}
+	return best, len(r.weights) > 0
+}
+
+// GetN returns the n highest scoring members for key.
+func (r *Rendezvous) GetN(key []byte, n int) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if n <= 0 {
+		return nil
+	}
+
+	type scored struct {
+		member string
+		score  float64
+	}
+	all := make([]scored, 0, len(r.weights))
+	for member, weight := range r.weights {
+		all = append(all, scored{member, r.score(member, weight, key)})
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].score == all[j].score {
+			return all[i].member < all[j].member
+		}
+		return all[i].score > all[j].score
+	})
+
+	members := make([]string, 0, min(n, len(all)))
+	for _, s := range all[:min(n, len(all))] {
+		members = append(members, s.member)
+	}
+	return members
+}
+
+// score uses the logarithmic method, weight / -ln(u) with u uniform in (0, 1),
+// which gives each member a share of keys proportional to its weight.
+func (r *Rendezvous) score(member string, weight int, key []byte) float64 {
+	buf := make([]byte, 0, len(member)+1+len(key))
+	buf = append(buf, member...)
+	buf = append(buf, 0)
+	buf = append(buf, key...)
+
+	h := mix.Mix64(r.algo.HashBytes(buf))
+	u := (float64(h>>11) + 0.5) / (1 << 53)
+	return float64(weight) / -math.Log(u)
+}
@@ -0,0 +1,159 @@
+package hashx
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/atlastore/belt/hashx/internal/mix"
+)
+
+var ErrInvalidWeight = errors.New("hashx: member weight must be positive")
+
+const DefaultVirtualNodes = 160
+
+// Placement maps keys onto a changing set of weighted members, such as nodes or disks.
+type Placement interface {
+	// Add inserts a member or updates its weight.
+	Add(member string, weight int) error
+	Remove(member string)
+	Members() []string
+	// Get returns the member that owns key, or false when there are no members.
+	Get(key []byte) (string, bool)
+	// GetN returns up to n distinct members for key in preference order, for replica placement.
+	GetN(key []byte, n int) []string
+}
+
+// Ring is a consistent hash ring with virtual nodes. A member with weight w owns
+// w times the configured number of virtual nodes, so adding or removing a member
+// only moves the keys that land on its points. Positions are passed through the SplitMix64 finalizer.
+// It is safe for concurrent use.
+type Ring struct {
+	mu      sync.RWMutex
+	algo    Hash64Algorithm
+	vnodes  int
+	weights map[string]int
+	points  []ringPoint
+}
+
+type ringPoint struct {
+	hash   uint64
+	member string
+}
+
+// NewRing creates an empty ring hashed with algo. vnodes is the number of virtual
+// nodes per unit of weight and defaults to DefaultVirtualNodes.
+func NewRing(algo Hash64Algorithm, vnodes ...int) (*Ring, error) {
+	if _, err := getHash64Func(algo); err != nil {
+		return nil, err
+	}
+
+	n := DefaultVirtualNodes
+	if len(vnodes) == 1 && vnodes[0] > 0 {
+		n = vnodes[0]
+	}
+
+	return &Ring{
+		algo:    algo,
+		vnodes:  n,
+		weights: make(map[string]int),
+	}, nil
+}
+
+func (r *Ring) Add(member string, weight int) error {
+	if weight <= 0 {
+		return ErrInvalidWeight
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.weights[member] = weight
+	r.rebuild()
+	return nil
+}
+
+func (r *Ring) Remove(member string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.weights[member]; !ok {
+		return
+	}
+	delete(r.weights, member)
+	r.rebuild()
+}
+
+func (r *Ring) Members() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return sortedMembers(r.weights)
+}
+
+func (r *Ring) Get(key []byte) (string, bool) {
+	members := r.GetN(key, 1)
+	if len(members) == 0 {
+		return "", false
+	}
+	return members[0], true
+}
+
+// GetN walks the ring clockwise from key and returns the first n distinct members.
+func (r *Ring) GetN(key []byte, n int) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.points) == 0 || n <= 0 {
+		return nil
+	}
+	n = min(n, len(r.weights))
+
+	h := mix.Mix64(r.algo.HashBytes(key))
+	start := sort.Search(len(r.points), func(i int) bool {
+		return r.points[i].hash >= h
+	})
+
+	members := make([]string, 0, n)
+	for i := 0; i < len(r.points) && len(members) < n; i++ {
+		p := r.points[(start+i)%len(r.points)]
+		if !contains(members, p.member) {
+			members = append(members, p.member)
+		}
+	}
+	return members
+}
+
+func (r *Ring) rebuild() {
+	r.points = r.points[:0]
+	for member, weight := range r.weights {
+		for i := 0; i < weight*r.vnodes; i++ {
+			r.points = append(r.points, ringPoint{
+				hash:   mix.Mix64(r.algo.HashString(member + "#" + strconv.Itoa(i))),
+				member: member,
+			})
+		}
+	}
+	sort.Slice(r.points, func(i, j int) bool {
+		if r.points[i].hash == r.points[j].hash {
+			return r.points[i].member < r.points[j].member
+		}
+		return r.points[i].hash < r.points[j].hash
+	})
+}
+
+func sortedMembers(weights map[string]int) []string {
+	members := make([]string, 0, len(weights))
+	for m := range weights {
+		members = append(members, m)
+	}
+	sort.Strings(members)
+	return members
+}
+
+func contains(members []string, member string) bool {
+	for _, m := range members {
+		if m == member {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,263 @@
+// Package sign signs and verifies hashx digests with Ed25519, ECDSA P-256 or RSA-PSS.
+//
+// A signature covers the digest together with the name of the algorithm that
+// produced it and the signature scheme, so it cannot be replayed for a digest of
+// another algorithm that happens to have the same bytes, or checked with a key of
+// another type. Signatures are detached and encode as scheme:algorithm:base64url.
+package sign
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/atlastore/belt/hashx"
+)
+
+var ErrUnsupportedKey = errors.New("sign: unsupported key type")
+var ErrSchemeMismatch = errors.New("sign: signature scheme does not match the key")
+var ErrAlgorithmMismatch = errors.New("sign: signature was made over another digest algorithm")
+var ErrWeakDigest = errors.New("sign: digest algorithm is not collision resistant")
+var ErrInvalidSignature = errors.New("sign: invalid signature")
+var ErrInvalidEncoding = errors.New("sign: invalid signature encoding")
+
+type Scheme string
+
+const (
+	Ed25519   Scheme = "ed25519"
+	ECDSAP256 Scheme = "ecdsa-p256"
+	// RSAPSS uses SHA-256 with a salt as long as the hash.
+	RSAPSS Scheme = "rsa-pss"
+)
+
+// signableDigests lists the collision resistant algorithms whose digests may be
+// signed. Anything else is rejected, since signing it would sign colliding data
+// too: broken digests, checksums, fast hashes, short-output PRFs such as SipHash,
+// Merkle tree roots and algorithms registered by applications.
+var signableDigests = map[string]bool{
+	string(hashx.SHA256):      true,
+	string(hashx.SHA384):      true,
+	string(hashx.SHA512):      true,
+	string(hashx.SHA3_256):    true,
+	string(hashx.SHA3_384):    true,
+	string(hashx.SHA3_512):    true,
+	string(hashx.HMAC_SHA256): true,
+	string(hashx.HMAC_SHA512): true,
+	string(hashx.Blake2s):     true,
+	string(hashx.Blake2b):     true,
+	string(hashx.Blake3):      true,
+	string(hashx.SHAKE128):    true,
+	string(hashx.SHAKE256):    true,
+	string(hashx.Blake3XOF):   true,
+}
+
+// MinDigestSize is the shortest digest Sign and Verify accept, so a short XOF
+// output or a truncated digest cannot be signed.
+const MinDigestSize = 32
+
+// Signature is a detached signature over a digest.
+type Signature struct {
+	Scheme    Scheme
+	Algorithm string
+	Value     []byte
+}
+
+// Sign signs sum with signer, which must hold an Ed25519, ECDSA P-256 or RSA key.
+func Sign(signer crypto.Signer, sum *hashx.HashSum) (*Signature, error) {
+	scheme, err := schemeOf(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	algo, err := checkSum(sum)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := message(scheme, algo, sum.Bytes())
+	var value []byte
+	switch scheme {
+	case Ed25519:
+		value, err = signer.Sign(rand.Reader, msg, crypto.Hash(0))
+	case ECDSAP256:
+		digest := sha256.Sum256(msg)
+		value, err = signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	case RSAPSS:
+		digest := sha256.Sum256(msg)
+		value, err = signer.Sign(rand.Reader, digest[:], pssOptions)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Signature{
+		Scheme:    scheme,
+		Algorithm: algo,
+		Value:     value,
+	}, nil
+}
+
+// SignData hashes data with algo and signs the digest.
+func SignData[T hashx.AnyHashAlgorithm](signer crypto.Signer, algo T, data []byte, key ...[]byte) (*Signature, error) {
+	sum, err := hashx.HashBytes(algo, data, key...)
+	if err != nil {
+		return nil, err
+	}
+	return Sign(signer, sum)
+}
+
+var pssOptions = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA256}
+
+// Verify checks sig over sum with pub. The scheme must match the type of pub and the
+// digest algorithm must match the one recorded in the signature.
+func Verify(pub crypto.PublicKey, sum *hashx.HashSum, sig *Signature) error {
+	scheme, err := schemeOf(pub)
+	if err != nil {
+		return err
+	}
+	if sig.Scheme != scheme {
+		return ErrSchemeMismatch
+	}
+	algo, err := checkSum(sum)
+	if err != nil {
+		return err
+	}
+	if recorded, err := checkDigest(sig.Algorithm); err != nil {
+		return err
+	} else if recorded != algo {
+		return ErrAlgorithmMismatch
+	}
+
+	msg := message(scheme, algo, sum.Bytes())
+	var ok bool
+	switch scheme {
+	case Ed25519:
+		ok = ed25519.Verify(pub.(ed25519.PublicKey), msg, sig.Value)
+	case ECDSAP256:
+		digest := sha256.Sum256(msg)
+		ok = ecdsa.VerifyASN1(pub.(*ecdsa.PublicKey), digest[:], sig.Value)
+	case RSAPSS:
+		digest := sha256.Sum256(msg)
+		ok = rsa.VerifyPSS(pub.(*rsa.PublicKey), crypto.SHA256, digest[:], sig.Value, pssOptions) == nil
+	}
+	if !ok {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// VerifyData hashes data with the algorithm recorded in sig and verifies the digest.
+func VerifyData(pub crypto.PublicKey, data []byte, sig *Signature, key ...[]byte) error {
+	if _, err := checkDigest(sig.Algorithm); err != nil {
+		return err
+	}
+	sum, err := hashx.HashBytes(sig.Algorithm, data, key...)
+	if err != nil {
+		return err
+	}
+	return Verify(pub, sum, sig)
+}
+
+func schemeOf(pub crypto.PublicKey) (Scheme, error) {
+	switch k := pub.(type) {
+	case ed25519.PublicKey:
+		return Ed25519, nil
+	case *ecdsa.PublicKey:
+		if k.Curve == elliptic.P256() {
+			return ECDSAP256, nil
+		}
+	case *rsa.PublicKey:
+		return RSAPSS, nil
+	}
+	return "", ErrUnsupportedKey
+}
+
+// checkDigest normalizes a digest algorithm name and rejects the ones that are
+// unsafe to sign, see signableDigests.
+func checkDigest(algo string) (string, error) {
+	algo = strings.ToLower(strings.ReplaceAll(algo, "_", "-"))
+	if signableDigests[algo] {
+		return algo, nil
+	}
+	if _, _, tree := hashx.ParseTreeAlgorithm(algo); !tree && !hashx.IsRegistered(algo) {
+		return "", &hashx.UnknownAlgorithmError{Algorithm: algo}
+	}
+	return "", ErrWeakDigest
+}
+
+// checkSum checks the algorithm and the length of a digest to sign or verify.
+func checkSum(sum *hashx.HashSum) (string, error) {
+	algo, err := checkDigest(sum.Algorithm())
+	if err != nil {
+		return "", err
+	}
+	if len(sum.Bytes()) < MinDigestSize {
+		return "", ErrWeakDigest
+	}
+	return algo, nil
+}
+
+// message is what gets signed: a context string, the scheme and the algorithm,
+// each length prefixed, followed by the digest.
+func message(scheme Scheme, algo string, digest []byte) []byte {
+	var b bytes.Buffer
+	b.WriteString("hashx signature v1\x00")
+	b.WriteByte(byte(len(scheme)))
+	b.WriteString(string(scheme))
+	b.WriteByte(byte(len(algo)))
+	b.WriteString(algo)
+	b.Write(digest)
+	return b.Bytes()
+}
+
+var sigEncoding = base64.RawURLEncoding
+
+// Encode returns the signature as scheme:algorithm:base64url.
+func (s *Signature) Encode() string {
+	return fmt.Sprintf("%s:%s:%s", s.Scheme, s.Algorithm, sigEncoding.EncodeToString(s.Value))
+}
+
+// ParseSignature parses the format produced by Signature.Encode.
+func ParseSignature(encoded string) (*Signature, error) {
+	fields := strings.Split(encoded, ":")
+	if len(fields) != 3 || fields[0] == "" || fields[1] == "" || fields[2] == "" {
+		return nil, ErrInvalidEncoding
+	}
+
+	switch Scheme(fields[0]) {
+	case Ed25519, ECDSAP256, RSAPSS:
+	default:
+		return nil, fmt.Errorf("%w: unknown scheme %q", ErrInvalidEncoding, fields[0])
+	}
+	value, err := sigEncoding.DecodeString(fields[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEncoding, err)
+	}
+
+	return &Signature{
+		Scheme:    Scheme(fields[0]),
+		Algorithm: fields[1],
+		Value:     value,
+	}, nil
+}
+
+func (s *Signature) MarshalText() ([]byte, error) {
+	return []byte(s.Encode()), nil
+}
+
+func (s *Signature) UnmarshalText(text []byte) error {
+	parsed, err := ParseSignature(string(text))
+	if err != nil {
+		return err
+	}
+	*s = *parsed
+	return nil
+}
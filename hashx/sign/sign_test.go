@@ -0,0 +1,188 @@
+package sign
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/atlastore/belt/hashx"
+)
+
+func newSigners(t *testing.T) map[Scheme]crypto.Signer {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[Scheme]crypto.Signer{Ed25519: edKey, ECDSAP256: ecKey, RSAPSS: rsaKey}
+}
+
+func TestSignVerify(t *testing.T) {
+	data := []byte("object manifest")
+	signers := newSigners(t)
+
+	for scheme, signer := range signers {
+		t.Run(string(scheme), func(t *testing.T) {
+			sum, _ := hashx.HashBytes(hashx.SHA256, data)
+			sig, err := Sign(signer, sum)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sig.Scheme != scheme || sig.Algorithm != string(hashx.SHA256) {
+				t.Fatalf("unexpected signature %s", sig.Encode())
+			}
+
+			if err := Verify(signer.Public(), sum, sig); err != nil {
+				t.Fatal(err)
+			}
+			if err := VerifyData(signer.Public(), data, sig); err != nil {
+				t.Fatal(err)
+			}
+			if err := VerifyData(signer.Public(), []byte("tampered"), sig); err != ErrInvalidSignature {
+				t.Fatalf("expected ErrInvalidSignature, got %v", err)
+			}
+
+			parsed, err := ParseSignature(sig.Encode())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := Verify(signer.Public(), sum, parsed); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestAlgorithmConfusion(t *testing.T) {
+	signers := newSigners(t)
+	signer := signers[Ed25519]
+
+	// SHA-256 and SHA3-256 digests have the same size.
+	sum, _ := hashx.HashString(hashx.SHA256, "data")
+	sig, _ := Sign(signer, sum)
+
+	forged, _ := hashx.NewHashSum(hashx.SHA3_256, sum.Bytes())
+	if err := Verify(signer.Public(), forged, sig); err != ErrAlgorithmMismatch {
+		t.Fatalf("expected ErrAlgorithmMismatch, got %v", err)
+	}
+
+	// Relabelling the signature does not help either, the algorithm is signed.
+	relabelled := *sig
+	relabelled.Algorithm = string(hashx.SHA3_256)
+	if err := Verify(signer.Public(), forged, &relabelled); err != ErrInvalidSignature {
+		t.Fatalf("expected ErrInvalidSignature, got %v", err)
+	}
+
+	// A signature is only checked with a key of its own scheme.
+	if err := Verify(signers[ECDSAP256].Public(), sum, sig); err != ErrSchemeMismatch {
+		t.Fatalf("expected ErrSchemeMismatch, got %v", err)
+	}
+
+	p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if _, err := Sign(p384, sum); err != ErrUnsupportedKey {
+		t.Fatalf("expected ErrUnsupportedKey, got %v", err)
+	}
+}
+
+func TestWeakDigests(t *testing.T) {
+	signer := newSigners(t)[Ed25519]
+
+	for _, algo := range []string{string(hashx.MD5), string(hashx.SHA1), string(hashx.CRC32), string(hashx.XXH3_64)} {
+		sum, _ := hashx.HashString(algo, "data")
+		if _, err := Sign(signer, sum); err != ErrWeakDigest {
+			t.Fatalf("%s: expected ErrWeakDigest, got %v", algo, err)
+		}
+	}
+
+	sig, _ := SignData(signer, hashx.SHA512, []byte("data"))
+	sig.Algorithm = string(hashx.MD5)
+	if err := VerifyData(signer.Public(), []byte("data"), sig); err != ErrWeakDigest {
+		t.Fatalf("expected ErrWeakDigest, got %v", err)
+	}
+
+	key := []byte("mac key")
+	mac, err := SignData(signer, hashx.HMAC_SHA256, []byte("data"), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyData(signer.Public(), []byte("data"), mac, key); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Only collision resistant digests of at least MinDigestSize bytes are signed,
+// whatever else is registered.
+func TestSignableDigests(t *testing.T) {
+	signer := newSigners(t)[Ed25519]
+
+	for algo, keySize := range map[hashx.KeyedHashAlgorithm]int{hashx.SipHash_2_4: 16, hashx.Highway: 32} {
+		if _, err := SignData(signer, algo, []byte("data"), make([]byte, keySize)); err != ErrWeakDigest {
+			t.Fatalf("%s: expected ErrWeakDigest, got %v", algo, err)
+		}
+	}
+	if !hashx.IsRegistered("in-house-checksum") {
+		hashx.Register("in-house-checksum", sha256.New)
+	}
+	if _, err := SignData(signer, "in-house-checksum", []byte("data")); err != ErrWeakDigest {
+		t.Fatalf("expected ErrWeakDigest for a registered algorithm, got %v", err)
+	}
+	tree, _ := hashx.HashReaderParallel(hashx.SHA256, strings.NewReader("data"), hashx.ParallelOptions{ChunkSize: 2})
+	if _, err := Sign(signer, tree); err != ErrWeakDigest {
+		t.Fatalf("%s: expected ErrWeakDigest, got %v", tree.Algorithm(), err)
+	}
+
+	short, _ := hashx.SHAKE128.HashString("data", 1)
+	if _, err := Sign(signer, short); err != ErrWeakDigest {
+		t.Fatalf("expected ErrWeakDigest for a 1 byte digest, got %v", err)
+	}
+	full, _ := hashx.SHAKE128.HashString("data", MinDigestSize)
+	sig, err := Sign(signer, full)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(signer.Public(), full, sig); err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(signer.Public(), short, sig); err != ErrWeakDigest {
+		t.Fatalf("expected ErrWeakDigest verifying a 1 byte digest, got %v", err)
+	}
+}
+
+func TestParseSignature(t *testing.T) {
+	sig := &Signature{Scheme: Ed25519, Algorithm: "blake3-xof", Value: []byte{1, 2, 3}}
+	encoded, err := json.Marshal(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(encoded) != `"ed25519:blake3-xof:AQID"` {
+		t.Fatalf("unexpected encoding %s", encoded)
+	}
+	var decoded Signature
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Encode() != sig.Encode() {
+		t.Fatalf("round trip mismatch: %s != %s", decoded.Encode(), sig.Encode())
+	}
+
+	for _, encoded := range []string{"", "ed25519:sha256", "dsa:sha256:AQID", "ed25519:sha256:!!", "ed25519::AQID"} {
+		if _, err := ParseSignature(encoded); !errors.Is(err, ErrInvalidEncoding) {
+			t.Fatalf("%q: expected ErrInvalidEncoding, got %v", encoded, err)
+		}
+	}
+}
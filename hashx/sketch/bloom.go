@@ -0,0 +1,157 @@
+package sketch
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+	"sync"
+
+	"github.com/atlastore/belt/hashx"
+	"github.com/atlastore/belt/hashx/internal/mix"
+)
+
+// Bloom is a Bloom filter. It never reports a key that was added as absent and
+// reports absent keys as present with the false positive rate it was sized for.
+type Bloom struct {
+	mu     sync.RWMutex
+	h      hasher
+	k      uint64
+	bits   []uint64
+	blocks uint64
+}
+
+// NewBloom sizes a Bloom filter for n keys with a false positive rate of p.
+func NewBloom(algo hashx.Hash64Algorithm, n uint64, p float64) (*Bloom, error) {
+	if n == 0 || p <= 0 || p >= 1 {
+		return nil, ErrInvalidParams
+	}
+
+	m := math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2))
+	k := math.Max(1, math.Round(m/float64(n)*math.Ln2))
+	return newBloom(algo, uint64(math.Ceil(m/64)), uint64(k))
+}
+
+func newBloom(algo hashx.Hash64Algorithm, blocks, k uint64) (*Bloom, error) {
+	h, err := newHasher(algo)
+	if err != nil {
+		return nil, err
+	}
+	if blocks == 0 || k == 0 || k > 64 {
+		return nil, ErrInvalidParams
+	}
+
+	return &Bloom{
+		h:      h,
+		k:      k,
+		bits:   make([]uint64, blocks),
+		blocks: blocks,
+	}, nil
+}
+
+// locations derives the k bit positions of key by double hashing (Kirsch and Mitzenmacher).
+func (b *Bloom) locations(key []byte, fn func(block uint64, mask uint64) bool) {
+	h1 := b.h.hash(key)
+	h2 := mix.Mix64(h1) | 1
+	m := b.blocks * 64
+	for i := uint64(0); i < b.k; i++ {
+		pos := (h1 + i*h2) % m
+		if !fn(pos/64, 1<<(pos%64)) {
+			return
+		}
+	}
+}
+
+func (b *Bloom) Add(key []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.locations(key, func(block, mask uint64) bool {
+		b.bits[block] |= mask
+		return true
+	})
+}
+
+// Test reports whether key might have been added.
+func (b *Bloom) Test(key []byte) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	found := true
+	b.locations(key, func(block, mask uint64) bool {
+		found = b.bits[block]&mask != 0
+		return found
+	})
+	return found
+}
+
+// EstimatedCount estimates the number of distinct keys added from the number of set bits.
+func (b *Bloom) EstimatedCount() uint64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	var set int
+	for _, w := range b.bits {
+		set += bits.OnesCount64(w)
+	}
+	m := float64(b.blocks * 64)
+	if set == int(m) {
+		return math.MaxUint64
+	}
+	return uint64(math.Round(-m / float64(b.k) * math.Log(1-float64(set)/m)))
+}
+
+// Merge adds every key of other to b. Both filters must have the same algorithm and size.
+func (b *Bloom) Merge(other *Bloom) error {
+	other.mu.RLock()
+	h, k, blocks := other.h, other.k, other.blocks
+	words := append([]uint64(nil), other.bits...)
+	other.mu.RUnlock()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if h != b.h || k != b.k || blocks != b.blocks {
+		return ErrIncompatible
+	}
+	for i, w := range words {
+		b.bits[i] |= w
+	}
+	return nil
+}
+
+func (b *Bloom) MarshalBinary() ([]byte, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	data := appendHeader(nil, kindBloom, b.h.algo)
+	data = binary.BigEndian.AppendUint64(data, b.k)
+	data = binary.BigEndian.AppendUint64(data, b.blocks)
+	for _, w := range b.bits {
+		data = binary.BigEndian.AppendUint64(data, w)
+	}
+	return data, nil
+}
+
+func (b *Bloom) UnmarshalBinary(data []byte) error {
+	d := &decoder{data: data}
+	algo := d.header(kindBloom)
+	k, blocks := d.uint64(), d.uint64()
+	if d.err != nil {
+		return d.err
+	}
+	if len(d.data)%8 != 0 || uint64(len(d.data)/8) != blocks {
+		return ErrInvalidEncoding
+	}
+
+	decoded, err := newBloom(algo, blocks, k)
+	if err != nil {
+		return err
+	}
+	for i := range decoded.bits {
+		decoded.bits[i] = d.uint64()
+	}
+	if err := d.finish(); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.h, b.k, b.bits, b.blocks = decoded.h, decoded.k, decoded.bits, decoded.blocks
+	return nil
+}
@@ -0,0 +1,76 @@
+package sketch
+
+import (
+	"testing"
+
+	"github.com/atlastore/belt/hashx"
+)
+
+func TestBloom(t *testing.T) {
+	const n = 10000
+
+	for _, algo := range []hashx.Hash64Algorithm{hashx.FNV64, hashx.CRC64, hashx.XXH3_64} {
+		t.Run(string(algo), func(t *testing.T) {
+			b, err := NewBloom(algo, n, 0.01)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < n; i++ {
+				b.Add(key(i))
+			}
+
+			for i := 0; i < n; i++ {
+				if !b.Test(key(i)) {
+					t.Fatalf("false negative for %s", key(i))
+				}
+			}
+			fp := 0
+			for i := n; i < 2*n; i++ {
+				if b.Test(key(i)) {
+					fp++
+				}
+			}
+			if rate := float64(fp) / n; rate > 0.02 {
+				t.Fatalf("false positive rate %.4f, expected about 0.01", rate)
+			}
+
+			if c := b.EstimatedCount(); c < n*95/100 || c > n*105/100 {
+				t.Fatalf("estimated %d keys, expected about %d", c, n)
+			}
+		})
+	}
+
+	if _, err := NewBloom(hashx.FNV64, 0, 0.01); err != ErrInvalidParams {
+		t.Fatalf("expected ErrInvalidParams, got %v", err)
+	}
+}
+
+func TestBloomMerge(t *testing.T) {
+	a, _ := NewBloom(hashx.FNV64, 1000, 0.01)
+	b, _ := NewBloom(hashx.FNV64, 1000, 0.01)
+	for i := 0; i < 500; i++ {
+		a.Add(key(i))
+		b.Add(key(500 + i))
+	}
+
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1000; i++ {
+		if !a.Test(key(i)) {
+			t.Fatalf("%s missing after merge", key(i))
+		}
+	}
+	if err := a.Merge(a); err != nil {
+		t.Fatal(err)
+	}
+
+	other, _ := NewBloom(hashx.CRC64, 1000, 0.01)
+	if err := a.Merge(other); err != ErrIncompatible {
+		t.Fatalf("expected ErrIncompatible, got %v", err)
+	}
+	bigger, _ := NewBloom(hashx.FNV64, 5000, 0.01)
+	if err := a.Merge(bigger); err != ErrIncompatible {
+		t.Fatalf("expected ErrIncompatible, got %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package sketch
+
+import (
+	"encoding/binary"
+	"math"
+	"sync"
+
+	"github.com/atlastore/belt/hashx"
+	"github.com/atlastore/belt/hashx/internal/mix"
+)
+
+// CountMin is a Count-Min sketch (Cormode and Muthukrishnan). Count never
+// underestimates, and overestimates by at most epsilon times the total count
+// with probability 1-delta.
+type CountMin struct {
+	mu     sync.RWMutex
+	h      hasher
+	width  uint64
+	depth  uint64
+	counts []uint64
+	total  uint64
+}
+
+// NewCountMin sizes a sketch for the error bound epsilon with confidence 1-delta.
+func NewCountMin(algo hashx.Hash64Algorithm, epsilon, delta float64) (*CountMin, error) {
+	if epsilon <= 0 || epsilon >= 1 || delta <= 0 || delta >= 1 {
+		return nil, ErrInvalidParams
+	}
+	width := math.Ceil(math.E / epsilon)
+	depth := math.Ceil(math.Log(1 / delta))
+	return newCountMin(algo, uint64(width), uint64(depth))
+}
+
+func newCountMin(algo hashx.Hash64Algorithm, width, depth uint64) (*CountMin, error) {
+	h, err := newHasher(algo)
+	if err != nil {
+		return nil, err
+	}
+	if width == 0 || depth == 0 || depth > 64 || width > math.MaxUint32 {
+		return nil, ErrInvalidParams
+	}
+	return &CountMin{
+		h:      h,
+		width:  width,
+		depth:  depth,
+		counts: make([]uint64, width*depth),
+	}, nil
+}
+
+// cells returns the counter index of key in every row, by double hashing. The
+// caller holds mu, since UnmarshalBinary replaces the hasher and the dimensions.
+func (cm *CountMin) cells(key []byte) []uint64 {
+	h1 := cm.h.hash(key)
+	h2 := mix.Mix64(h1) | 1
+	cells := make([]uint64, cm.depth)
+	for i := range cells {
+		cells[i] = uint64(i)*cm.width + (h1+uint64(i)*h2)%cm.width
+	}
+	return cells
+}
+
+// Add increments the count of key by n.
+func (cm *CountMin) Add(key []byte, n uint64) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cells := cm.cells(key)
+	for _, c := range cells {
+		cm.counts[c] += n
+	}
+	cm.total += n
+}
+
+// Count returns the estimated count of key.
+func (cm *CountMin) Count(key []byte) uint64 {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	cells := cm.cells(key)
+	count := uint64(math.MaxUint64)
+	for _, c := range cells {
+		count = min(count, cm.counts[c])
+	}
+	return count
+}
+
+// Total returns the sum of all counts added.
+func (cm *CountMin) Total() uint64 {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.total
+}
+
+// Merge adds the counts of other to cm. Both must have the same algorithm and dimensions.
+func (cm *CountMin) Merge(other *CountMin) error {
+	other.mu.RLock()
+	h, width, depth := other.h, other.width, other.depth
+	counts := append([]uint64(nil), other.counts...)
+	total := other.total
+	other.mu.RUnlock()
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	if h != cm.h || width != cm.width || depth != cm.depth {
+		return ErrIncompatible
+	}
+	for i, c := range counts {
+		cm.counts[i] += c
+	}
+	cm.total += total
+	return nil
+}
+
+func (cm *CountMin) MarshalBinary() ([]byte, error) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	data := appendHeader(nil, kindCountMin, cm.h.algo)
+	data = binary.BigEndian.AppendUint64(data, cm.width)
+	data = binary.BigEndian.AppendUint64(data, cm.depth)
+	data = binary.BigEndian.AppendUint64(data, cm.total)
+	for _, c := range cm.counts {
+		data = binary.BigEndian.AppendUint64(data, c)
+	}
+	return data, nil
+}
+
+func (cm *CountMin) UnmarshalBinary(data []byte) error {
+	d := &decoder{data: data}
+	algo := d.header(kindCountMin)
+	width, depth, total := d.uint64(), d.uint64(), d.uint64()
+	if d.err != nil {
+		return d.err
+	}
+	// Check the size before allocating, the dimensions are bounded by newCountMin.
+	if depth > 64 || width > math.MaxUint32 || uint64(len(d.data)) != width*depth*8 {
+		return ErrInvalidEncoding
+	}
+
+	decoded, err := newCountMin(algo, width, depth)
+	if err != nil {
+		return err
+	}
+	for i := range decoded.counts {
+		decoded.counts[i] = d.uint64()
+	}
+	if err := d.finish(); err != nil {
+		return err
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.h, cm.width, cm.depth, cm.counts, cm.total = decoded.h, decoded.width, decoded.depth, decoded.counts, total
+	return nil
+}
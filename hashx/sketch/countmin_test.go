@@ -0,0 +1,58 @@
+package sketch
+
+import (
+	"testing"
+
+	"github.com/atlastore/belt/hashx"
+)
+
+func TestCountMin(t *testing.T) {
+	const epsilon = 0.001
+
+	cm, err := NewCountMin(hashx.FNV64, epsilon, 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A skewed workload: key i is seen i%100 times.
+	truth := make(map[int]uint64)
+	for i := 0; i < 10000; i++ {
+		cm.Add(key(i), uint64(i%100))
+		truth[i] = uint64(i % 100)
+	}
+
+	bound := uint64(epsilon * float64(cm.Total()))
+	over := 0
+	for i, want := range truth {
+		got := cm.Count(key(i))
+		if got < want {
+			t.Fatalf("%s: count %d underestimates %d", key(i), got, want)
+		}
+		if got-want > bound {
+			over++
+		}
+	}
+	if over > len(truth)/100 {
+		t.Fatalf("%d of %d counts exceed the error bound", over, len(truth))
+	}
+}
+
+func TestCountMinMerge(t *testing.T) {
+	a, _ := NewCountMin(hashx.XXH3_64, 0.01, 0.01)
+	b, _ := NewCountMin(hashx.XXH3_64, 0.01, 0.01)
+	a.Add([]byte("x"), 3)
+	b.Add([]byte("x"), 4)
+	b.Add([]byte("y"), 1)
+
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	if a.Count([]byte("x")) < 7 || a.Total() != 8 {
+		t.Fatalf("unexpected counts after merge: x=%d total=%d", a.Count([]byte("x")), a.Total())
+	}
+
+	other, _ := NewCountMin(hashx.XXH3_64, 0.001, 0.01)
+	if err := a.Merge(other); err != ErrIncompatible {
+		t.Fatalf("expected ErrIncompatible, got %v", err)
+	}
+}
@@ -0,0 +1,274 @@
+package sketch
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/bits"
+	"math/rand/v2"
+	"sync"
+
+	"github.com/atlastore/belt/hashx"
+	"github.com/atlastore/belt/hashx/internal/mix"
+)
+
+var ErrFull = errors.New("sketch: cuckoo filter is full")
+
+const (
+	bucketSize = 4
+	maxKicks   = 500
+)
+
+// Cuckoo is a Cuckoo filter (Fan et al.) with 16-bit fingerprints and four slots
+// per bucket. Unlike a Bloom filter it supports deleting keys, and its false
+// positive rate is about 0.012% at full load.
+type Cuckoo struct {
+	mu      sync.RWMutex
+	h       hasher
+	buckets [][bucketSize]uint16
+	count   uint64
+	// victim holds a fingerprint that could not be placed after maxKicks relocations,
+	// so that a failed insert never loses a key that was already present.
+	victim      uint16
+	victimIndex uint64
+}
+
+// NewCuckoo sizes a Cuckoo filter for capacity keys.
+func NewCuckoo(algo hashx.Hash64Algorithm, capacity uint64) (*Cuckoo, error) {
+	if capacity == 0 {
+		return nil, ErrInvalidParams
+	}
+	// Partial-key cuckoo hashing needs a power of two number of buckets and fills
+	// to about 95% before inserts start failing.
+	n := (capacity*100/95 + bucketSize - 1) / bucketSize
+	return newCuckoo(algo, uint64(1)<<bits.Len64(max(n, 1)-1))
+}
+
+func newCuckoo(algo hashx.Hash64Algorithm, buckets uint64) (*Cuckoo, error) {
+	h, err := newHasher(algo)
+	if err != nil {
+		return nil, err
+	}
+	if buckets == 0 || buckets&(buckets-1) != 0 {
+		return nil, ErrInvalidParams
+	}
+	return &Cuckoo{
+		h:       h,
+		buckets: make([][bucketSize]uint16, buckets),
+	}, nil
+}
+
+func (c *Cuckoo) mask() uint64 {
+	return uint64(len(c.buckets)) - 1
+}
+
+// locate returns the fingerprint of key and its first bucket. The caller holds mu,
+// since UnmarshalBinary replaces the hasher and the buckets.
+func (c *Cuckoo) locate(key []byte) (uint16, uint64) {
+	h := c.h.hash(key)
+	fp := uint16(h >> 48)
+	if fp == 0 {
+		fp = 1
+	}
+	return fp, h & c.mask()
+}
+
+// alternate returns the other bucket of a fingerprint. It is its own inverse.
+func (c *Cuckoo) alternate(i uint64, fp uint16) uint64 {
+	return (i ^ mix.Mix64(uint64(fp))) & c.mask()
+}
+
+// Add inserts key. It returns ErrFull when no slot could be freed for it.
+func (c *Cuckoo) Add(key []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fp, i := c.locate(key)
+	return c.insert(fp, i)
+}
+
+func (c *Cuckoo) insert(fp uint16, i1 uint64) error {
+	if c.victim != 0 {
+		return ErrFull
+	}
+
+	i2 := c.alternate(i1, fp)
+	if c.put(i1, fp) || c.put(i2, fp) {
+		c.count++
+		return nil
+	}
+
+	i := i1
+	if rand.IntN(2) == 1 {
+		i = i2
+	}
+	for n := 0; n < maxKicks; n++ {
+		slot := rand.IntN(bucketSize)
+		fp, c.buckets[i][slot] = c.buckets[i][slot], fp
+		i = c.alternate(i, fp)
+		if c.put(i, fp) {
+			c.count++
+			return nil
+		}
+	}
+
+	c.victim, c.victimIndex = fp, i
+	c.count++
+	return nil
+}
+
+func (c *Cuckoo) put(i uint64, fp uint16) bool {
+	for slot, v := range c.buckets[i] {
+		if v == 0 {
+			c.buckets[i][slot] = fp
+			return true
+		}
+	}
+	return false
+}
+
+// Test reports whether key might have been added.
+func (c *Cuckoo) Test(key []byte) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	fp, i1 := c.locate(key)
+
+	i2 := c.alternate(i1, fp)
+	if c.victim == fp && (c.victimIndex == i1 || c.victimIndex == i2) {
+		return true
+	}
+	for _, v := range c.buckets[i1] {
+		if v == fp {
+			return true
+		}
+	}
+	for _, v := range c.buckets[i2] {
+		if v == fp {
+			return true
+		}
+	}
+	return false
+}
+
+// Delete removes one occurrence of key and reports whether it was found.
+// Only keys that were added may be deleted, or another key sharing the
+// fingerprint could be removed instead.
+func (c *Cuckoo) Delete(key []byte) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fp, i1 := c.locate(key)
+
+	i2 := c.alternate(i1, fp)
+	if c.victim == fp && (c.victimIndex == i1 || c.victimIndex == i2) {
+		c.victim = 0
+		c.count--
+		return true
+	}
+	for _, i := range []uint64{i1, i2} {
+		for slot, v := range c.buckets[i] {
+			if v == fp {
+				c.buckets[i][slot] = 0
+				c.count--
+				c.reinsertVictim()
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// reinsertVictim moves the victim back into the table once a slot was freed.
+func (c *Cuckoo) reinsertVictim() {
+	if c.victim == 0 {
+		return
+	}
+	fp, i := c.victim, c.victimIndex
+	c.victim = 0
+	c.count--
+	c.insert(fp, i)
+}
+
+// Len returns the number of keys in the filter.
+func (c *Cuckoo) Len() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.count
+}
+
+// Merge adds every key of other to c. Both filters must have the same algorithm
+// and size. It returns ErrFull if c runs out of space, with part of other merged.
+func (c *Cuckoo) Merge(other *Cuckoo) error {
+	other.mu.RLock()
+	h := other.h
+	buckets := append([][bucketSize]uint16(nil), other.buckets...)
+	victim, victimIndex := other.victim, other.victimIndex
+	other.mu.RUnlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if h != c.h || len(buckets) != len(c.buckets) {
+		return ErrIncompatible
+	}
+	for i, bucket := range buckets {
+		for _, fp := range bucket {
+			if fp == 0 {
+				continue
+			}
+			if err := c.insert(fp, uint64(i)); err != nil {
+				return err
+			}
+		}
+	}
+	if victim != 0 {
+		return c.insert(victim, victimIndex)
+	}
+	return nil
+}
+
+func (c *Cuckoo) MarshalBinary() ([]byte, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	data := appendHeader(nil, kindCuckoo, c.h.algo)
+	data = binary.BigEndian.AppendUint64(data, uint64(len(c.buckets)))
+	data = binary.BigEndian.AppendUint64(data, c.count)
+	data = binary.BigEndian.AppendUint16(data, c.victim)
+	data = binary.BigEndian.AppendUint64(data, c.victimIndex)
+	for _, bucket := range c.buckets {
+		for _, fp := range bucket {
+			data = binary.BigEndian.AppendUint16(data, fp)
+		}
+	}
+	return data, nil
+}
+
+func (c *Cuckoo) UnmarshalBinary(data []byte) error {
+	d := &decoder{data: data}
+	algo := d.header(kindCuckoo)
+	n, count := d.uint64(), d.uint64()
+	victim := d.bytes(2, 0)
+	victimIndex := d.uint64()
+	if d.err != nil {
+		return d.err
+	}
+	if len(d.data)%(2*bucketSize) != 0 || uint64(len(d.data)/(2*bucketSize)) != n || victimIndex >= n {
+		return ErrInvalidEncoding
+	}
+
+	decoded, err := newCuckoo(algo, n)
+	if err != nil {
+		return err
+	}
+	for i := range decoded.buckets {
+		for slot := range decoded.buckets[i] {
+			decoded.buckets[i][slot] = binary.BigEndian.Uint16(d.bytes(2, 0))
+		}
+	}
+	if err := d.finish(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.h, c.buckets, c.count = decoded.h, decoded.buckets, count
+	c.victim, c.victimIndex = binary.BigEndian.Uint16(victim), victimIndex
+	return nil
+}
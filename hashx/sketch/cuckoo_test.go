@@ -0,0 +1,104 @@
+package sketch
+
+import (
+	"testing"
+
+	"github.com/atlastore/belt/hashx"
+)
+
+func TestCuckoo(t *testing.T) {
+	const n = 10000
+
+	c, err := NewCuckoo(hashx.FNV64, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		if err := c.Add(key(i)); err != nil {
+			t.Fatalf("insert %d: %v", i, err)
+		}
+	}
+	if c.Len() != n {
+		t.Fatalf("expected %d keys, got %d", n, c.Len())
+	}
+
+	for i := 0; i < n; i++ {
+		if !c.Test(key(i)) {
+			t.Fatalf("false negative for %s", key(i))
+		}
+	}
+	fp := 0
+	for i := n; i < 2*n; i++ {
+		if c.Test(key(i)) {
+			fp++
+		}
+	}
+	if rate := float64(fp) / n; rate > 0.001 {
+		t.Fatalf("false positive rate %.4f is too high", rate)
+	}
+
+	for i := 0; i < n; i += 2 {
+		if !c.Delete(key(i)) {
+			t.Fatalf("%s could not be deleted", key(i))
+		}
+	}
+	for i := 1; i < n; i += 2 {
+		if !c.Test(key(i)) {
+			t.Fatalf("deleting other keys removed %s", key(i))
+		}
+	}
+	if c.Len() != n/2 {
+		t.Fatalf("expected %d keys, got %d", n/2, c.Len())
+	}
+}
+
+func TestCuckooFull(t *testing.T) {
+	c, _ := NewCuckoo(hashx.XXH3_64, 64)
+
+	added := 0
+	for i := 0; ; i++ {
+		if err := c.Add(key(i)); err == ErrFull {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		added++
+	}
+
+	// Every key that was accepted must still be found.
+	for i := 0; i < added; i++ {
+		if !c.Test(key(i)) {
+			t.Fatalf("%s lost after the filter filled up", key(i))
+		}
+	}
+	if uint64(added) != c.Len() {
+		t.Fatalf("accepted %d keys but Len is %d", added, c.Len())
+	}
+
+	c.Delete(key(0))
+	if err := c.Add(key(0)); err != nil {
+		t.Fatalf("expected room after a delete, got %v", err)
+	}
+}
+
+func TestCuckooMerge(t *testing.T) {
+	a, _ := NewCuckoo(hashx.FNV64, 1000)
+	b, _ := NewCuckoo(hashx.FNV64, 1000)
+	for i := 0; i < 300; i++ {
+		a.Add(key(i))
+		b.Add(key(300 + i))
+	}
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 600; i++ {
+		if !a.Test(key(i)) {
+			t.Fatalf("%s missing after merge", key(i))
+		}
+	}
+
+	other, _ := NewCuckoo(hashx.FNV64, 100000)
+	if err := a.Merge(other); err != ErrIncompatible {
+		t.Fatalf("expected ErrIncompatible, got %v", err)
+	}
+}
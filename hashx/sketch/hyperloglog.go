@@ -0,0 +1,143 @@
+package sketch
+
+import (
+	"math"
+	"math/bits"
+	"sync"
+
+	"github.com/atlastore/belt/hashx"
+)
+
+const (
+	MinPrecision = 4
+	MaxPrecision = 18
+)
+
+// HyperLogLog estimates the number of distinct keys (Flajolet et al.) in 2^precision
+// bytes, with a standard error of about 1.04/sqrt(2^precision): 0.8% at precision 14.
+type HyperLogLog struct {
+	mu        sync.RWMutex
+	h         hasher
+	precision uint8
+	registers []uint8
+}
+
+// NewHyperLogLog creates a HyperLogLog with 2^precision registers.
+func NewHyperLogLog(algo hashx.Hash64Algorithm, precision uint8) (*HyperLogLog, error) {
+	h, err := newHasher(algo)
+	if err != nil {
+		return nil, err
+	}
+	if precision < MinPrecision || precision > MaxPrecision {
+		return nil, ErrInvalidParams
+	}
+	return &HyperLogLog{
+		h:         h,
+		precision: precision,
+		registers: make([]uint8, 1<<precision),
+	}, nil
+}
+
+func (hll *HyperLogLog) Add(key []byte) {
+	hll.mu.Lock()
+	defer hll.mu.Unlock()
+
+	x := hll.h.hash(key)
+	i := x >> (64 - hll.precision)
+	// Rank of the first set bit in the remaining bits, capped by a sentinel bit.
+	rank := uint8(bits.LeadingZeros64(x<<hll.precision|1<<(hll.precision-1))) + 1
+	if rank > hll.registers[i] {
+		hll.registers[i] = rank
+	}
+}
+
+// Count returns the estimated number of distinct keys added.
+func (hll *HyperLogLog) Count() uint64 {
+	hll.mu.RLock()
+	defer hll.mu.RUnlock()
+
+	m := float64(len(hll.registers))
+	var sum float64
+	var zeros int
+	for _, r := range hll.registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+
+	estimate := alpha(m) * m * m / sum
+	// Small range correction: linear counting is more accurate while registers are empty.
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(math.Round(estimate))
+}
+
+func alpha(m float64) float64 {
+	switch m {
+	case 16:
+		return 0.673
+	case 32:
+		return 0.697
+	case 64:
+		return 0.709
+	default:
+		return 0.7213 / (1 + 1.079/m)
+	}
+}
+
+// Merge makes hll count the union of both sets. Both must have the same algorithm and precision.
+func (hll *HyperLogLog) Merge(other *HyperLogLog) error {
+	other.mu.RLock()
+	h, precision := other.h, other.precision
+	registers := append([]uint8(nil), other.registers...)
+	other.mu.RUnlock()
+
+	hll.mu.Lock()
+	defer hll.mu.Unlock()
+	if h != hll.h || precision != hll.precision {
+		return ErrIncompatible
+	}
+	for i, r := range registers {
+		hll.registers[i] = max(hll.registers[i], r)
+	}
+	return nil
+}
+
+func (hll *HyperLogLog) MarshalBinary() ([]byte, error) {
+	hll.mu.RLock()
+	defer hll.mu.RUnlock()
+
+	data := appendHeader(nil, kindHyperLogLog, hll.h.algo)
+	data = append(data, hll.precision)
+	return append(data, hll.registers...), nil
+}
+
+func (hll *HyperLogLog) UnmarshalBinary(data []byte) error {
+	d := &decoder{data: data}
+	algo := d.header(kindHyperLogLog)
+	precision := d.bytes(1, 0)
+	if d.err != nil {
+		return d.err
+	}
+
+	decoded, err := NewHyperLogLog(algo, precision[0])
+	if err != nil {
+		return err
+	}
+	copy(decoded.registers, d.bytes(len(decoded.registers), 0))
+	if err := d.finish(); err != nil {
+		return err
+	}
+	for _, r := range decoded.registers {
+		if r > 64-decoded.precision+1 {
+			return ErrInvalidEncoding
+		}
+	}
+
+	hll.mu.Lock()
+	defer hll.mu.Unlock()
+	hll.h, hll.precision, hll.registers = decoded.h, decoded.precision, decoded.registers
+	return nil
+}
@@ -0,0 +1,56 @@
+package sketch
+
+import (
+	"math"
+	"testing"
+
+	"github.com/atlastore/belt/hashx"
+)
+
+func TestHyperLogLog(t *testing.T) {
+	for _, algo := range []hashx.Hash64Algorithm{hashx.FNV64, hashx.XXH3_64} {
+		hll, err := NewHyperLogLog(algo, 14)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hll.Count() != 0 {
+			t.Fatalf("expected an empty count, got %d", hll.Count())
+		}
+
+		for _, n := range []int{100, 10000, 200000} {
+			for i := 0; i < n; i++ {
+				hll.Add(key(i))
+				hll.Add(key(i))
+			}
+			// Standard error is 0.8% at precision 14, allow four of them.
+			if got := hll.Count(); math.Abs(float64(got)-float64(n))/float64(n) > 0.035 {
+				t.Fatalf("%s: counted %d distinct keys, expected about %d", algo, got, n)
+			}
+		}
+	}
+
+	if _, err := NewHyperLogLog(hashx.FNV64, 3); err != ErrInvalidParams {
+		t.Fatalf("expected ErrInvalidParams, got %v", err)
+	}
+}
+
+func TestHyperLogLogMerge(t *testing.T) {
+	a, _ := NewHyperLogLog(hashx.FNV64, 12)
+	b, _ := NewHyperLogLog(hashx.FNV64, 12)
+	for i := 0; i < 30000; i++ {
+		a.Add(key(i))
+		b.Add(key(20000 + i))
+	}
+
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	if got := a.Count(); math.Abs(float64(got)-50000)/50000 > 0.07 {
+		t.Fatalf("counted %d distinct keys in the union, expected about 50000", got)
+	}
+
+	other, _ := NewHyperLogLog(hashx.FNV64, 14)
+	if err := a.Merge(other); err != ErrIncompatible {
+		t.Fatalf("expected ErrIncompatible, got %v", err)
+	}
+}
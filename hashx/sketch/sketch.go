@@ -0,0 +1,103 @@
+// Package sketch provides probabilistic data structures over any hashx.Hash64Algorithm:
+// a Bloom filter and a Cuckoo filter for membership, HyperLogLog for counting
+// distinct keys and a Count-Min sketch for frequencies.
+//
+// Every structure is safe for concurrent use, supports binary serialization
+// and can be merged with another one built with the same algorithm and parameters.
+package sketch
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/atlastore/belt/hashx"
+	"github.com/atlastore/belt/hashx/internal/mix"
+)
+
+var ErrIncompatible = errors.New("sketch: sketches have different algorithms or parameters")
+var ErrInvalidParams = errors.New("sketch: invalid parameters")
+var ErrInvalidEncoding = errors.New("sketch: invalid encoding")
+
+const (
+	magic   = "hxsk"
+	version = 1
+)
+
+type kind byte
+
+const (
+	kindBloom kind = iota + 1
+	kindCuckoo
+	kindHyperLogLog
+	kindCountMin
+)
+
+// hasher hashes keys with a Hash64Algorithm followed by the SplitMix64 finalizer.
+type hasher struct {
+	algo hashx.Hash64Algorithm
+}
+
+func newHasher(algo hashx.Hash64Algorithm) (hasher, error) {
+	if class, ok := hashx.ClassOf(algo); !ok || class != hashx.ClassHash64 {
+		return hasher{}, hashx.ErrUnsupported
+	}
+	return hasher{algo: algo}, nil
+}
+
+func (h hasher) hash(key []byte) uint64 {
+	return mix.Mix64(h.algo.HashBytes(key))
+}
+
+// appendHeader starts an encoding with the magic, the kind and the algorithm name.
+func appendHeader(b []byte, k kind, algo hashx.Hash64Algorithm) []byte {
+	b = append(b, magic...)
+	b = append(b, version, byte(k), byte(len(algo)))
+	return append(b, algo...)
+}
+
+// decoder reads an encoding, remembering the first error.
+type decoder struct {
+	data []byte
+	err  error
+}
+
+func (d *decoder) header(k kind) hashx.Hash64Algorithm {
+	if len(d.data) < len(magic)+3 || string(d.data[:len(magic)]) != magic {
+		d.err = ErrInvalidEncoding
+		return ""
+	}
+	d.data = d.data[len(magic):]
+	if d.data[0] != version || kind(d.data[1]) != k {
+		d.err = fmt.Errorf("%w: unexpected version %d or kind %d", ErrInvalidEncoding, d.data[0], d.data[1])
+		return ""
+	}
+	return hashx.Hash64Algorithm(d.bytes(int(d.data[2]), 3))
+}
+
+// bytes returns n bytes after skipping skip bytes.
+func (d *decoder) bytes(n, skip int) []byte {
+	if d.err != nil || len(d.data) < skip+n {
+		d.err = ErrInvalidEncoding
+		return nil
+	}
+	b := d.data[skip : skip+n]
+	d.data = d.data[skip+n:]
+	return b
+}
+
+func (d *decoder) uint64() uint64 {
+	b := d.bytes(8, 0)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint64(b)
+}
+
+// finish reports an error if bytes are left over.
+func (d *decoder) finish() error {
+	if d.err == nil && len(d.data) != 0 {
+		d.err = fmt.Errorf("%w: %d trailing bytes", ErrInvalidEncoding, len(d.data))
+	}
+	return d.err
+}
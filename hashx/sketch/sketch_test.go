@@ -0,0 +1,191 @@
+package sketch
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/atlastore/belt/hashx"
+)
+
+type sketch interface {
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+var (
+	_ sketch = (*Bloom)(nil)
+	_ sketch = (*Cuckoo)(nil)
+	_ sketch = (*HyperLogLog)(nil)
+	_ sketch = (*CountMin)(nil)
+)
+
+func key(i int) []byte {
+	return []byte(fmt.Sprintf("object-%d", i))
+}
+
+func newSketches(t *testing.T, algo hashx.Hash64Algorithm) map[string]sketch {
+	bloom, err := NewBloom(algo, 1000, 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cuckoo, err := NewCuckoo(algo, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hll, err := NewHyperLogLog(algo, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cm, err := NewCountMin(algo, 0.01, 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 500; i++ {
+		bloom.Add(key(i))
+		cuckoo.Add(key(i))
+		hll.Add(key(i))
+		cm.Add(key(i), uint64(i))
+	}
+	return map[string]sketch{"bloom": bloom, "cuckoo": cuckoo, "hyperloglog": hll, "countmin": cm}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	for name, s := range newSketches(t, hashx.XXH3_64) {
+		t.Run(name, func(t *testing.T) {
+			data, err := s.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var decoded sketch
+			switch s.(type) {
+			case *Bloom:
+				decoded = new(Bloom)
+			case *Cuckoo:
+				decoded = new(Cuckoo)
+			case *HyperLogLog:
+				decoded = new(HyperLogLog)
+			case *CountMin:
+				decoded = new(CountMin)
+			}
+			if err := decoded.UnmarshalBinary(data); err != nil {
+				t.Fatal(err)
+			}
+			again, _ := decoded.MarshalBinary()
+			if string(again) != string(data) {
+				t.Fatal("round trip changed the encoding")
+			}
+
+			if err := decoded.UnmarshalBinary(data[:len(data)-1]); !errors.Is(err, ErrInvalidEncoding) {
+				t.Fatalf("expected ErrInvalidEncoding for a truncated encoding, got %v", err)
+			}
+			if err := decoded.UnmarshalBinary(append(data, 0)); !errors.Is(err, ErrInvalidEncoding) {
+				t.Fatalf("expected ErrInvalidEncoding for trailing bytes, got %v", err)
+			}
+			if err := decoded.UnmarshalBinary(nil); !errors.Is(err, ErrInvalidEncoding) {
+				t.Fatalf("expected ErrInvalidEncoding for no data, got %v", err)
+			}
+		})
+	}
+
+	// Each kind only decodes its own encoding.
+	bloom, _ := newSketches(t, hashx.FNV64)["bloom"].MarshalBinary()
+	if err := new(HyperLogLog).UnmarshalBinary(bloom); !errors.Is(err, ErrInvalidEncoding) {
+		t.Fatalf("expected ErrInvalidEncoding, got %v", err)
+	}
+}
+
+func TestConcurrentUse(t *testing.T) {
+	bloom, _ := NewBloom(hashx.FNV64, 10000, 0.01)
+	cuckoo, _ := NewCuckoo(hashx.FNV64, 10000)
+	hll, _ := NewHyperLogLog(hashx.FNV64, 12)
+	cm, _ := NewCountMin(hashx.FNV64, 0.001, 0.01)
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				bloom.Add(key(w*1000 + i))
+				cuckoo.Add(key(w*1000 + i))
+				hll.Add(key(w*1000 + i))
+				cm.Add(key(i), 1)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				bloom.Test(key(i))
+				cuckoo.Test(key(i))
+				hll.Count()
+				cm.Count(key(i))
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i := 0; i < 4000; i++ {
+		if !bloom.Test(key(i)) || !cuckoo.Test(key(i)) {
+			t.Fatalf("%s was added but not found", key(i))
+		}
+	}
+	if cm.Total() != 4000 {
+		t.Fatalf("expected a total of 4000, got %d", cm.Total())
+	}
+}
+
+func TestConcurrentUnmarshal(t *testing.T) {
+	encoded := map[string][]byte{}
+	for name, s := range newSketches(t, hashx.CRC64) {
+		encoded[name], _ = s.MarshalBinary()
+	}
+	bloom, _ := NewBloom(hashx.FNV64, 10000, 0.01)
+	cuckoo, _ := NewCuckoo(hashx.FNV64, 10000)
+	hll, _ := NewHyperLogLog(hashx.FNV64, 12)
+	cm, _ := NewCountMin(hashx.FNV64, 0.001, 0.01)
+
+	// Decoding replaces the hasher and the dimensions under use.
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			bloom.Add(key(i))
+			cuckoo.Add(key(i))
+			hll.Add(key(i))
+			cm.Add(key(i), 1)
+			bloom.Test(key(i))
+			cuckoo.Test(key(i))
+			cuckoo.Delete(key(i))
+			cm.Count(key(i))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for _, err := range []error{
+			bloom.UnmarshalBinary(encoded["bloom"]),
+			cuckoo.UnmarshalBinary(encoded["cuckoo"]),
+			hll.UnmarshalBinary(encoded["hyperloglog"]),
+			cm.UnmarshalBinary(encoded["countmin"]),
+		} {
+			if err != nil {
+				t.Error(err)
+			}
+		}
+	}()
+	wg.Wait()
+}
+
+func TestUnsupportedAlgorithm(t *testing.T) {
+	if _, err := NewBloom(hashx.Hash64Algorithm("sha256"), 10, 0.1); err != hashx.ErrUnsupported {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+	if _, err := NewHyperLogLog(hashx.Hash64Algorithm("nope"), 10); err != hashx.ErrUnsupported {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+}
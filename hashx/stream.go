@@ -0,0 +1,137 @@
+package hashx
+
+import (
+	"fmt"
+	"hash"
+	"io"
+)
+
+// MismatchError is returned by VerifyingReader when the data read does not match
+// the expected sum. It matches ErrMismatch with errors.Is.
+type MismatchError struct {
+	Expected *HashSum
+	Actual   *HashSum
+}
+
+func (e *MismatchError) Error() string {
+	return fmt.Sprintf("hashx: digest mismatch, expected %s but got %s", e.Expected.Encode(), e.Actual.Encode())
+}
+
+// Unwrap lets errors.Is match ErrMismatch.
+func (e *MismatchError) Unwrap() error {
+	return ErrMismatch
+}
+
+// VerifyingReader hashes data as it is read and checks it against an expected sum
+// once the underlying reader is exhausted. Instead of io.EOF, the final Read
+// returns a *MismatchError if the digests differ, so a consumer that reads until
+// EOF gets end-to-end integrity without a second pass over the data.
+type VerifyingReader struct {
+	r        io.Reader
+	h        hash.Hash
+	expected *HashSum
+	err      error
+}
+
+// NewVerifyingReader wraps r to verify it against expected.
+// Extendable-output sums are checked at the length of the expected digest.
+func NewVerifyingReader(r io.Reader, expected *HashSum, key ...[]byte) (*VerifyingReader, error) {
+	var h hash.Hash
+	if class, _ := ClassOf(expected.algo); class == ClassXOF {
+		fn, err := GetXOF(XOFAlgorithm(expected.algo))
+		if err != nil {
+			return nil, err
+		}
+		h = &xofHash{xof: fn(), size: len(expected.data)}
+	} else {
+		fn, err := GetHash(expected.algo, key...)
+		if err != nil {
+			return nil, err
+		}
+		h = fn()
+	}
+
+	return &VerifyingReader{
+		r:        r,
+		h:        h,
+		expected: expected,
+	}, nil
+}
+
+func (vr *VerifyingReader) Read(p []byte) (int, error) {
+	if vr.err != nil {
+		return 0, vr.err
+	}
+
+	n, err := vr.r.Read(p)
+	vr.h.Write(p[:n])
+	if err == io.EOF {
+		err = vr.verify()
+	}
+	if err != nil {
+		vr.err = err
+	}
+	return n, err
+}
+
+// Verified reports whether the reader reached EOF and the digest matched.
+func (vr *VerifyingReader) Verified() bool {
+	return vr.err == io.EOF
+}
+
+func (vr *VerifyingReader) verify() error {
+	actual := &HashSum{
+		data: vr.h.Sum(nil),
+		algo: vr.expected.algo,
+	}
+	if err := vr.expected.compare(actual); err != nil {
+		if err == ErrMismatch {
+			return &MismatchError{Expected: vr.expected, Actual: actual}
+		}
+		return err
+	}
+	return io.EOF
+}
+
+// TeeWriter writes to an underlying writer and hashes exactly the bytes it accepted,
+// so the digest of a file written to disk is available without reading it back.
+// To compute several digests at once use multi.NewTeeWriter.
+type TeeWriter struct {
+	w       io.Writer
+	h       hash.Hash
+	algo    string
+	written int64
+}
+
+func NewTeeWriter[T AnyHashAlgorithm](w io.Writer, algo T, key ...[]byte) (*TeeWriter, error) {
+	fn, err := GetHash(algo, key...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TeeWriter{
+		w:    w,
+		h:    fn(),
+		algo: string(algo),
+	}, nil
+}
+
+func (tw *TeeWriter) Write(p []byte) (int, error) {
+	n, err := tw.w.Write(p)
+	tw.h.Write(p[:n])
+	tw.written += int64(n)
+	return n, err
+}
+
+// Written returns the number of bytes written so far.
+func (tw *TeeWriter) Written() int64 {
+	return tw.written
+}
+
+// Sum returns the digest of everything written so far.
+func (tw *TeeWriter) Sum() *HashSum {
+	return &HashSum{
+		data: tw.h.Sum(nil),
+		algo: tw.algo,
+	}
+}
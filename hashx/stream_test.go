@@ -0,0 +1,99 @@
+package hashx
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestVerifyingReader(t *testing.T) {
+	data := strings.Repeat("replicate me ", 1000)
+	sum, _ := HashString(SHA256, data)
+
+	vr, err := NewVerifyingReader(iotest.HalfReader(strings.NewReader(data)), sum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := io.ReadAll(vr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != data || !vr.Verified() {
+		t.Fatal("expected the data to pass through and verify")
+	}
+
+	vr, _ = NewVerifyingReader(strings.NewReader(data+"!"), sum)
+	_, err = io.ReadAll(vr)
+	var mismatch *MismatchError
+	if !errors.As(err, &mismatch) || !errors.Is(err, ErrMismatch) {
+		t.Fatalf("expected MismatchError, got %v", err)
+	}
+	if mismatch.Expected != sum || mismatch.Actual.Algorithm() != sum.Algorithm() {
+		t.Fatalf("unexpected sums in %v", mismatch)
+	}
+	if _, err := vr.Read(make([]byte, 1)); err != mismatch {
+		t.Fatalf("expected the error to be sticky, got %v", err)
+	}
+	if vr.Verified() {
+		t.Fatal("mismatched reader reported as verified")
+	}
+
+	xof, _ := SHAKE256.HashString(data, 20)
+	vr, _ = NewVerifyingReader(strings.NewReader(data), xof)
+	if _, err := io.ReadAll(vr); err != nil {
+		t.Fatal(err)
+	}
+
+	key := []byte("a key of any length up to 64 b")
+	mac, _ := HashString(Blake2b, data, key)
+	if _, err := NewVerifyingReader(strings.NewReader(data), mac); err != ErrKeyRequired {
+		t.Fatalf("expected ErrKeyRequired, got %v", err)
+	}
+	vr, _ = NewVerifyingReader(strings.NewReader(data), mac, key)
+	if _, err := io.ReadAll(vr); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTeeWriter(t *testing.T) {
+	var buf bytes.Buffer
+	tw, err := NewTeeWriter(&buf, SHA512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.Copy(tw, strings.NewReader("written to disk"))
+
+	if tw.Written() != int64(buf.Len()) {
+		t.Fatalf("expected %d bytes written, got %d", buf.Len(), tw.Written())
+	}
+	if err := tw.Sum().VerifyBytes(buf.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the bytes the destination accepted are hashed.
+	short := &limitWriter{n: 4}
+	tw, _ = NewTeeWriter(short, SHA256)
+	if _, err := tw.Write([]byte("truncated")); err != io.ErrShortWrite {
+		t.Fatalf("expected io.ErrShortWrite, got %v", err)
+	}
+	if err := tw.Sum().VerifyString("trun"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type limitWriter struct {
+	n int
+}
+
+func (w *limitWriter) Write(p []byte) (int, error) {
+	if len(p) > w.n {
+		n := w.n
+		w.n = 0
+		return n, io.ErrShortWrite
+	}
+	w.n -= len(p)
+	return len(p), nil
+}
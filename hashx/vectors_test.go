@@ -0,0 +1,131 @@
+package hashx
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+type vector struct {
+	input  string
+	key    string // hex
+	digest string // hex
+}
+
+func seq(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i)
+	}
+	return hex.EncodeToString(b)
+}
+
+// Published vectors: FIPS 180/202 examples, RFC 1320/1321/4231, the BLAKE2, BLAKE3,
+// HighwayHash, xxHash, wyhash and SipHash reference test suites, Guava's MurmurHash3
+// tests, the Go standard library CRC/FNV tables and the CRC RevEng catalogue.
+var vectors = map[string]vector{
+	string(SHA1):     {input: "abc", digest: "a9993e364706816aba3e25717850c26c9cd0d89d"},
+	string(SHA224):   {input: "abc", digest: "23097d223405d8228642a477bda255b32aadbce4bda0b3f7e36c9da7"},
+	string(SHA256):   {input: "abc", digest: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+	string(SHA384):   {input: "abc", digest: "cb00753f45a35e8bb5a03d699ac65007272c32ab0eded1631a8b605a43ff5bed8086072ba1e7cc2358baeca134c825a7"},
+	string(SHA512):   {input: "abc", digest: "ddaf35a193617abacc417349ae20413112e6fa4e89a97ea20a9eeee64b55d39a2192992a274fc1a836ba3c23a3feebbd454d4423643ce80e2a9ac94fa54ca49f"},
+	string(SHA3_224): {input: "abc", digest: "e642824c3f8cf24ad09234ee7d3c766fc9a3a5168d0c94ad73b46fdf"},
+	string(SHA3_256): {input: "abc", digest: "3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532"},
+	string(SHA3_384): {input: "abc", digest: "ec01498288516fc926459f58e2c6ad8df9b473cb0fc08c2596da7cf0e49be4b298d88cea927ac7f539f1edf228376d25"},
+	string(SHA3_512): {input: "abc", digest: "b751850b1a57168a5693cd924b6b096e08f621827444f70d884f5d0240d2712e10e116e9192af3c91a7ec57647e3934057340b4cf408d5a56592f8274eec53f0"},
+	string(MD4):      {input: "abc", digest: "a448017aaf21d8525fc10ae87aa6729d"},
+	string(MD5):      {input: "abc", digest: "900150983cd24fb0d6963f7d28e17f72"},
+	string(XXHash):   {input: "abc", digest: "44bc2cf5ad770999"},
+
+	string(HMAC_SHA256): {input: "what do ya want for nothing?", key: hex.EncodeToString([]byte("Jefe")), digest: "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"},
+	string(HMAC_SHA512): {input: "what do ya want for nothing?", key: hex.EncodeToString([]byte("Jefe")), digest: "164b7a7bfcf819e2e395fbe73b56e0a387bd64222e831fd610270cd7ea2505549758bf75c05a994a6d034f65f8f0e6fdcaeab1a34d4a6b4b636e070a38bce737"},
+	string(Blake2b):     {key: seq(64), digest: "10ebb67700b1868efb4417987acf4690ae9d972fb7a590c2f02871799aaa4786b5e996e8f0f4eb981fc214b005f42d2ff4233499391653df7aefcbc13fc51568"},
+	string(Blake2s):     {key: seq(32), digest: "48a8997da407876b3d79c0d92325ad3b89cbb754d86ab71aee047ad345fd2c49"},
+	string(Blake3):      {key: hex.EncodeToString([]byte("whats the Elvish word for friend")), digest: "92b2b75604ed3c761f9d6f62392c8a9227ad0ea3f09573e783f1498a4ed60d26"},
+	string(Highway):     {key: seq(32), digest: "f574c8c22a4844dd1f35c713730146d9ff1487b9ccbeaeb3f41d75453123da41"},
+
+	string(CRC32): {input: "abc", digest: "352441c2"},
+	string(FNV32): {input: "abc", digest: "1a47e90b"},
+	string(CRC64): {input: "abc", digest: "3776c42000000000"},
+	string(FNV64): {input: "abc", digest: "e71fa2190541574b"},
+
+	string(XXH64):       {digest: "ef46db3751d8e999"},
+	string(XXH3_64):     {digest: "2d06800538d394c2"},
+	string(XXH3_128):    {digest: "99aa06d3014798d86001c324468d497f"},
+	string(WyHash):      {digest: "0409638ee2bde459"},
+	string(Murmur3_32):  {input: "The quick brown fox jumps over the lazy dog", digest: "2e4ff723"},
+	string(Murmur3_128): {input: "The quick brown fox jumps over the lazy dog", digest: "e34bbc7bbc071b6c7a433ca9c49a9347"},
+	string(SipHash_2_4): {input: "\x00\x01\x02\x03\x04\x05\x06\x07\x08\x09\x0a\x0b\x0c\x0d\x0e", key: seq(16), digest: "e545be4961ca29a1"},
+
+	// Check values from the CRC RevEng catalogue.
+	string(CRC32C):     {input: "123456789", digest: "e3069283"},
+	string(CRC64_ECMA): {input: "123456789", digest: "995dc9bbdf1939fa"},
+	string(CRC64_NVME): {input: "123456789", digest: "ae8b14860a799888"},
+
+	string(SHAKE128):  {input: "abc", digest: "5881092dd818bf5cf8a3ddb793fbcba74097d5c526a6d35f97b83351940f2cc8"},
+	string(SHAKE256):  {input: "abc", digest: "483366601360a8771c6863080cc4114d8db44530f8f1e1ee4f94ea37e78b5739d5a15bef186a5386c75744c0527e1faa9f8726e462a12a4feb06bd8801e751e4"},
+	string(Blake3XOF): {digest: "af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262"},
+}
+
+func TestVectors(t *testing.T) {
+	for _, info := range Algorithms() {
+		// KDFs do not hash through HashString, they have their own vectors.
+		if strings.HasPrefix(info.Name, "test-") || info.Class == ClassKDF {
+			continue
+		}
+
+		t.Run(info.Name, func(t *testing.T) {
+			v, ok := vectors[info.Name]
+			if !ok {
+				t.Fatalf("no test vector for registered algorithm %s", info.Name)
+			}
+
+			var key [][]byte
+			if v.key != "" {
+				k, _ := hex.DecodeString(v.key)
+				key = append(key, k)
+			}
+
+			sum, err := HashString(HashAlgorithm(info.Name), v.input, key...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hex.EncodeToString(sum.data); got != v.digest {
+				t.Fatalf("expected %s, got %s", v.digest, got)
+			}
+		})
+	}
+}
+
+func TestXOFLength(t *testing.T) {
+	// BLAKE3 reference vector for the empty input, extended to 131 bytes.
+	want := "af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262e00f03e7b69af26b7faaf09fcd333050338ddfe085b8cc869ca98b206c08243a26f5487789e8f660afe6c99ef9e0c52b92e7393024a80459cf91f476f9ffdbda7001c22e159b402631f277ca96f2defdf1078282314e763699a31c5363165421cce14d"
+
+	sum, err := Blake3XOF.HashString("", len(want)/2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(sum.data); got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+
+	parsed, err := ParseHashSum(sum.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parsed.VerifyString(""); err != nil {
+		t.Fatal(err)
+	}
+
+	short, err := SHAKE256.HashString("abc", 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(short.data); got != vectors[string(SHAKE256)].digest[:32] {
+		t.Fatalf("unexpected SHAKE256 prefix %s", got)
+	}
+
+	if _, err := SHAKE128.HashString("abc", 0); err != ErrInvalidSize {
+		t.Fatalf("expected ErrInvalidSize, got %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+package hashx
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var ErrMismatch = errors.New("hashx: digest mismatch")
+var ErrInvalidEncoding = errors.New("hashx: invalid hash sum encoding")
+
+// UnknownAlgorithmError is returned when a parsed sum names an algorithm that is not registered.
+type UnknownAlgorithmError struct {
+	Algorithm string
+}
+
+func (e *UnknownAlgorithmError) Error() string {
+	return fmt.Sprintf("hashx: unknown algorithm %q", e.Algorithm)
+}
+
+// Unwrap lets errors.Is match ErrUnsupported.
+func (e *UnknownAlgorithmError) Unwrap() error {
+	return ErrUnsupported
+}
+
+// DigestLengthError is returned when a digest does not have the size its algorithm produces.
+type DigestLengthError struct {
+	Algorithm string
+	Expected  int
+	Got       int
+}
+
+func (e *DigestLengthError) Error() string {
+	return fmt.Sprintf("hashx: %s digest must be %d bytes but got %d", e.Algorithm, e.Expected, e.Got)
+}
+
+// ParseHashSum parses the algo:hex format produced by HashSum.Encode.
+// The digest length is checked for every algorithm that has a fixed size without a key.
+func ParseHashSum(encoded string) (*HashSum, error) {
+	algo, digest, ok := strings.Cut(encoded, ":")
+	if !ok || algo == "" || digest == "" {
+		return nil, ErrInvalidEncoding
+	}
+
+	if _, ok := lookupSumAlgorithm(algo); !ok {
+		return nil, &UnknownAlgorithmError{Algorithm: algo}
+	}
+
+	data, err := hex.DecodeString(digest)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEncoding, err)
+	}
+
+	return newHashSum(algo, data)
+}
+
+// lookupSumAlgorithm looks up the algorithm of a sum. Merkle tree roots named by
+// TreeAlgorithm resolve to the entry of the algorithm the tree is built with.
+func lookupSumAlgorithm(algo string) (algorithmEntry, bool) {
+	if entry, ok := lookup(algo); ok {
+		return entry, true
+	}
+	base, _, ok := ParseTreeAlgorithm(algo)
+	if !ok {
+		return algorithmEntry{}, false
+	}
+	entry, ok := lookup(base)
+	if !ok || entry.class == ClassXOF || entry.class == ClassKDF {
+		return algorithmEntry{}, false
+	}
+	return entry, true
+}
+
+// newHashSum builds a sum for a decoded digest, checking the algorithm and digest length.
+func newHashSum(algo string, data []byte) (*HashSum, error) {
+	entry, ok := lookupSumAlgorithm(algo)
+	if !ok {
+		return nil, &UnknownAlgorithmError{Algorithm: algo}
+	}
+
+	if entry.newHash != nil && entry.class != ClassXOF {
+		if size := entry.newHash().Size(); size != len(data) {
+			return nil, &DigestLengthError{Algorithm: algo, Expected: size, Got: len(data)}
+		}
+	}
+
+	return &HashSum{
+		data: data,
+		algo: algo,
+	}, nil
+}
+
+// Verify parses encoded and checks it against data.
+func Verify(encoded string, data []byte, key ...[]byte) error {
+	sum, err := ParseHashSum(encoded)
+	if err != nil {
+		return err
+	}
+	return sum.VerifyBytes(data, key...)
+}
+
+// VerifyReader parses encoded and checks it against everything read from r.
+func VerifyReader(encoded string, r io.Reader, key ...[]byte) error {
+	sum, err := ParseHashSum(encoded)
+	if err != nil {
+		return err
+	}
+	return sum.VerifyReader(r, key...)
+}
+
+// VerifyBytes re-hashes data with the sum's algorithm and returns ErrMismatch if the digests differ.
+func (h HashSum) VerifyBytes(data []byte, key ...[]byte) error {
+	return h.VerifyReader(bytes.NewReader(data), key...)
+}
+
+// VerifyString is VerifyBytes for a string.
+func (h HashSum) VerifyString(str string, key ...[]byte) error {
+	return h.VerifyBytes([]byte(str), key...)
+}
+
+// VerifyReader re-hashes everything read from r and returns ErrMismatch if the digests differ.
+// Extendable-output sums are re-hashed to the length of the stored digest and
+// Merkle tree roots with the chunk size in their algorithm name.
+func (h HashSum) VerifyReader(r io.Reader, key ...[]byte) error {
+	var actual *HashSum
+	var err error
+	if class, _ := ClassOf(h.algo); class == ClassXOF {
+		actual, err = XOFAlgorithm(h.algo).HashReader(r, len(h.data))
+	} else if _, _, ok := ParseTreeAlgorithm(h.algo); ok && !IsRegistered(h.algo) {
+		actual, err = hashReaderTree(h.algo, r, key...)
+	} else {
+		actual, err = HashReader(HashAlgorithm(h.algo), r, key...)
+	}
+	if err != nil {
+		return err
+	}
+	return h.compare(actual)
+}
+
+func (h HashSum) compare(actual *HashSum) error {
+	if len(actual.data) != len(h.data) {
+		return &DigestLengthError{Algorithm: h.algo, Expected: len(actual.data), Got: len(h.data)}
+	}
+	if subtle.ConstantTimeCompare(actual.data, h.data) != 1 {
+		return ErrMismatch
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package hashx
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseHashSum(t *testing.T) {
+	data := "the quick brown fox"
+
+	sum, err := HashString(SHA256, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := ParseHashSum(sum.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Encode() != sum.Encode() {
+		t.Fatalf("round trip mismatch: %s != %s", parsed.Encode(), sum.Encode())
+	}
+
+	if err := Verify(sum.Encode(), []byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyReader(sum.Encode(), strings.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := parsed.VerifyString("the quick brown dog"); err != ErrMismatch {
+		t.Fatalf("expected ErrMismatch, got %v", err)
+	}
+
+	var unknown *UnknownAlgorithmError
+	if _, err := ParseHashSum("nope:abcd"); !errors.As(err, &unknown) || !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("expected UnknownAlgorithmError, got %v", err)
+	}
+
+	var length *DigestLengthError
+	if _, err := ParseHashSum("sha256:abcd"); !errors.As(err, &length) || length.Expected != 32 {
+		t.Fatalf("expected DigestLengthError, got %v", err)
+	}
+
+	for _, bad := range []string{"", "sha256", "sha256:", ":abcd", "sha256:zz"} {
+		if _, err := ParseHashSum(bad); !errors.Is(err, ErrInvalidEncoding) {
+			t.Fatalf("%q: expected ErrInvalidEncoding, got %v", bad, err)
+		}
+	}
+}
+
+func TestVerifyKeyed(t *testing.T) {
+	key := []byte("secret")
+
+	sum, err := HashString(HMAC_SHA256, "payload", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Verify(sum.Encode(), []byte("payload"), key); err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(sum.Encode(), []byte("payload"), []byte("other")); err != ErrMismatch {
+		t.Fatalf("expected ErrMismatch, got %v", err)
+	}
+	if err := Verify(sum.Encode(), []byte("payload")); err != ErrKeyRequired {
+		t.Fatalf("expected ErrKeyRequired, got %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package hashx
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// wyp is the default secret of wyhash final 3.
+var wyp = [4]uint64{0xa0761d6478bd642f, 0xe7037ed1a0b428db, 0x8ebc6af09c88c6e3, 0x589965cc75374cc3}
+
+// wyhash is a hash.Hash64 for wyhash final 3. The function is not incremental,
+// so input is buffered until Sum is called.
+type wyhash struct {
+	seed uint64
+	buf  []byte
+}
+
+func newWyhash(seed uint64) *wyhash {
+	return &wyhash{seed: seed}
+}
+
+func (w *wyhash) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	return len(p), nil
+}
+
+func (w *wyhash) Sum(b []byte) []byte {
+	return binary.BigEndian.AppendUint64(b, w.Sum64())
+}
+
+func (w *wyhash) Sum64() uint64 {
+	return wyhashSum(w.buf, w.seed)
+}
+
+func (w *wyhash) Reset() {
+	w.buf = w.buf[:0]
+}
+
+func (w *wyhash) Size() int {
+	return 8
+}
+
+func (w *wyhash) BlockSize() int {
+	return 48
+}
+
+func wymix(a, b uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	return hi ^ lo
+}
+
+func wyr8(p []byte) uint64 {
+	return binary.LittleEndian.Uint64(p)
+}
+
+func wyr4(p []byte) uint64 {
+	return uint64(binary.LittleEndian.Uint32(p))
+}
+
+func wyhashSum(p []byte, seed uint64) uint64 {
+	n := len(p)
+	seed ^= wymix(seed^wyp[0], wyp[1])
+
+	var a, b uint64
+	switch {
+	case n == 0:
+	case n < 4:
+		a = uint64(p[0])<<16 | uint64(p[n>>1])<<8 | uint64(p[n-1])
+	case n <= 16:
+		a = wyr4(p)<<32 | wyr4(p[(n>>3)<<2:])
+		b = wyr4(p[n-4:])<<32 | wyr4(p[n-4-((n>>3)<<2):])
+	default:
+		i := 0
+		if n > 48 {
+			see1, see2 := seed, seed
+			for ; n-i > 48; i += 48 {
+				seed = wymix(wyr8(p[i:])^wyp[1], wyr8(p[i+8:])^seed)
+				see1 = wymix(wyr8(p[i+16:])^wyp[2], wyr8(p[i+24:])^see1)
+				see2 = wymix(wyr8(p[i+32:])^wyp[3], wyr8(p[i+40:])^see2)
+			}
+			seed ^= see1 ^ see2
+		}
+		for ; n-i > 16; i += 16 {
+			seed = wymix(wyr8(p[i:])^wyp[1], wyr8(p[i+8:])^seed)
+		}
+		// The last 16 bytes, which may overlap the previous block.
+		a = wyr8(p[n-16:])
+		b = wyr8(p[n-8:])
+	}
+
+	b, a = bits.Mul64(a^wyp[1], b^seed)
+	return wymix(a^wyp[0]^uint64(n), b^wyp[1])
+}
@@ -1,21 +1,110 @@
 package key
 
 import (
+	"encoding/base64"
 	"encoding/binary"
 	"encoding/hex"
 	"fmt"
 	"reflect"
+	"strings"
 )
 
+// Decode decodes a hex encoded key. Parse accepts every Encoding.
+// T may be a concrete key type or an interface such as VersionedKey or Locator.
 func Decode[T VersionedKey](encoded string) (T, error) {
 	var zero T
 	raw, err := hex.DecodeString(encoded)
 	if err != nil {
-		return zero, fmt.Errorf("key: failed to hex decode: %v", err)
+		return zero, fmt.Errorf("%w: failed to hex decode: %v", ErrInvalidKey, err)
 	}
 
+	return decodeAs[T](raw)
+}
+
+// Parse decodes a key in any Encoding, detected from its prefix, so hex keys
+// written before the other encodings existed keep working.
+func Parse[T VersionedKey](encoded string) (T, error) {
+	var zero T
+	raw, err := decodeText(encoded)
+	if err != nil {
+		return zero, err
+	}
+
+	return decodeAs[T](raw)
+}
+
+// DecodeAny decodes a key of any registered version in any Encoding, for callers
+// that receive keys of mixed versions. Keys that implement Locator can be routed
+// without knowing their type.
+func DecodeAny(encoded string) (VersionedKey, Version, error) {
+	raw, err := decodeText(encoded)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	key, err := decodeBinary(raw)
+	if err != nil {
+		return nil, 0, err
+	}
+	return key, key.Version(), nil
+}
+
+// PeekVersion returns the version of an encoded key in any Encoding without
+// decoding or validating the rest of it. Apart from base58, whose characters all
+// depend on the whole key, only the first few characters are read.
+func PeekVersion(encoded string) (Version, error) {
+	enc, err := DetectEncoding(encoded)
+	if err != nil {
+		return 0, err
+	}
+
+	// The characters holding the first two bytes.
+	var header []byte
+	switch enc {
+	case Hex:
+		if len(encoded) >= 4 {
+			header, err = hex.DecodeString(encoded[:4])
+		}
+	case Base32:
+		// 4 characters carry 20 bits, so the last 4 need not be zero.
+		if len(encoded) >= 5 {
+			header, err = crockford.DecodeString(crockfordAliases.Replace(strings.ToUpper(encoded[1:5])))
+		}
+	case Base58:
+		header, err = base58Decode(encoded[1:])
+	case Base64URL:
+		if len(encoded) >= 4 {
+			header, err = base64.RawURLEncoding.DecodeString(encoded[1:4])
+		}
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%w: failed to %s decode: %v", ErrInvalidKey, enc, err)
+	}
+	if len(header) < 2 {
+		return 0, fmt.Errorf("%w: too short", ErrInvalidKey)
+	}
+
+	return Version(binary.BigEndian.Uint16(header[:2])), nil
+}
+
+func decodeAs[T VersionedKey](raw []byte) (T, error) {
+	var zero T
+	key, err := decodeBinary(raw)
+	if err != nil {
+		return zero, err
+	}
+
+	typedKey, ok := key.(T)
+	if !ok {
+		return zero, fmt.Errorf("key: decoded a version %d key, which is not a %v", key.Version(), reflect.TypeFor[T]())
+	}
+
+	return typedKey, nil
+}
+
+func decodeBinary(raw []byte) (VersionedKey, error) {
 	if len(raw) < 2 {
-		return zero, fmt.Errorf("key: too short")
+		return nil, fmt.Errorf("%w: too short", ErrInvalidKey)
 	}
 
 	version := Version(binary.BigEndian.Uint16(raw[:2]))
@@ -24,23 +113,18 @@ func Decode[T VersionedKey](encoded string) (T, error) {
 	registryMu.RLock()
 	codec, ok := codecRegistry[version]
 	registryMu.RUnlock()
-	
+
 	if !ok {
-		return zero, fmt.Errorf("key: no decoder registered for version %d", version)
+		return nil, fmt.Errorf("%w: no decoder registered for version %d", ErrInvalidKey, version)
 	}
 
 	key, err := codec.Decode(payload)
 	if err != nil {
-		return zero, err
+		return nil, err
 	}
-
-	expectedType := reflect.TypeOf(zero)
-
-	if reflect.TypeOf(key) != expectedType {
-		return zero, fmt.Errorf("key: decoded key is not of excepted type %T", zero)
+	if key == nil || key.Version() != version {
+		return nil, fmt.Errorf("key: decoder for version %d returned a different key", version)
 	}
 
-	typedKey := key.(T)
-
-	return typedKey, nil
-}
\ No newline at end of file
+	return key, nil
+}
@@ -0,0 +1,94 @@
+package key
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeAny(t *testing.T) {
+	kf := testFactory()
+	for v, sample := range sampleKeys() {
+		for _, enc := range encodings {
+			encoded, err := kf.EncodeKeyAs(sample, enc)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			k, version, err := DecodeAny(encoded)
+			if err != nil {
+				t.Fatalf("%d/%s: %v", v, enc, err)
+			}
+			if version != v || k.Version() != v {
+				t.Fatalf("%d/%s: decoded version %d", v, enc, version)
+			}
+			if !reflect.DeepEqual(k, sample) {
+				t.Fatalf("%d/%s: decoded %+v, expected %+v", v, enc, k, sample)
+			}
+
+			loc, ok := k.(Locator)
+			if !ok {
+				t.Fatalf("version %d does not implement Locator", v)
+			}
+			if loc.GetNodeID() != kf.nodeID || loc.GetDiskID() != kf.diskId {
+				t.Fatalf("%d/%s: located at %d/%d", v, enc, loc.GetNodeID(), loc.GetDiskID())
+			}
+
+			peeked, err := PeekVersion(encoded)
+			if err != nil || peeked != v {
+				t.Fatalf("%d/%s: peeked %d, %v", v, enc, peeked, err)
+			}
+		}
+	}
+}
+
+// PeekVersion only reads the header, so it works on keys that fail to decode.
+func TestPeekVersion(t *testing.T) {
+	kf := testFactory()
+	encoded, err := kf.EncodeKeyAs(sampleKeys()[V2], Base64URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	corrupt := encoded[:len(encoded)-1] + "A"
+	if corrupt == encoded {
+		corrupt = encoded[:len(encoded)-1] + "B"
+	}
+
+	if _, _, err := DecodeAny(corrupt); err == nil {
+		t.Fatal("decoded a corrupt key")
+	}
+	if v, err := PeekVersion(corrupt); err != nil || v != V2 {
+		t.Fatalf("peeked %d, %v", v, err)
+	}
+
+	if v, err := PeekVersion("ff00"); err != nil || v != 0xff00 {
+		t.Fatalf("peeked %d, %v", v, err)
+	}
+	if _, _, err := DecodeAny("ff00"); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("expected ErrInvalidKey for an unregistered version, got %v", err)
+	}
+
+	for _, s := range []string{"", "0", "000", "c000", "u00", "z", "z1", "x0000", "0g00"} {
+		if _, err := PeekVersion(s); err == nil {
+			t.Errorf("peeked a version from %q", s)
+		}
+	}
+}
+
+func TestDecodeInterface(t *testing.T) {
+	kf := testFactory()
+	encoded, err := kf.EncodeKey(sampleKeys()[V2])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if k, err := Decode[VersionedKey](encoded); err != nil || k.Version() != V2 {
+		t.Fatalf("Decode[VersionedKey] = %v, %v", k, err)
+	}
+	if k, err := Parse[Locator](encoded); err != nil || k.GetNodeID() != kf.nodeID {
+		t.Fatalf("Parse[Locator] = %v, %v", k, err)
+	}
+	if _, err := Decode[*KeyV1](encoded); err == nil {
+		t.Fatal("decoded a V2 key as V1")
+	}
+}
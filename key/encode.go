@@ -1,11 +1,17 @@
 package key
 
 import (
-	"encoding/hex"
 	"fmt"
 )
 
+// EncodeKey encodes k with the factory's IDs in the factory's encoding, hex unless
+// KeyFactoryParams.Encoding says otherwise.
 func (kf *KeyFactory) EncodeKey(k VersionedKey) (string, error) {
+	return kf.EncodeKeyAs(k, kf.encoding)
+}
+
+// EncodeKeyAs is EncodeKey with an explicit encoding.
+func (kf *KeyFactory) EncodeKeyAs(k VersionedKey, enc Encoding) (string, error) {
 	k.SetIDs(kf.nodeID, kf.diskId)
 
 	registryMu.RLock()
@@ -20,5 +26,5 @@ func (kf *KeyFactory) EncodeKey(k VersionedKey) (string, error) {
 		return "", err
 	}
 
-	return hex.EncodeToString(body), nil
-}
\ No newline at end of file
+	return enc.Encode(body)
+}
@@ -0,0 +1,225 @@
+package key
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Encoding is the text form of an encoded key.
+//
+// Hex keys are written as is, as they always have been. The other encodings start
+// with a one character prefix so Parse can tell them apart: c for Crockford base32,
+// z for base58 and u for base64url, the last two as in multibase.
+type Encoding int
+
+const (
+	// Hex is lowercase hexadecimal, twice the length of the binary key.
+	Hex Encoding = iota
+	// Base32 is Crockford's base32, decoded case insensitively with I and L read
+	// as 1 and O as 0. Like hex, it sorts in the same order as the binary keys.
+	Base32
+	// Base58 is the Bitcoin alphabet, which avoids look-alike characters.
+	Base58
+	// Base64URL is the URL and file name safe base64 alphabet without padding.
+	// Like base58 it is about 4/3 the length of the binary key.
+	Base64URL
+)
+
+const (
+	base32Prefix    = 'c'
+	base58Prefix    = 'z'
+	base64URLPrefix = 'u'
+)
+
+var ErrUnknownEncoding = errors.New("key: unknown encoding")
+
+var crockford = base32.NewEncoding("0123456789ABCDEFGHJKMNPQRSTVWXYZ").WithPadding(base32.NoPadding)
+
+var crockfordAliases = strings.NewReplacer("I", "1", "L", "1", "O", "0")
+
+func (e Encoding) String() string {
+	switch e {
+	case Hex:
+		return "hex"
+	case Base32:
+		return "base32"
+	case Base58:
+		return "base58"
+	case Base64URL:
+		return "base64url"
+	default:
+		return fmt.Sprintf("Encoding(%d)", int(e))
+	}
+}
+
+// Encode returns the text form of a binary key.
+func (e Encoding) Encode(raw []byte) (string, error) {
+	switch e {
+	case Hex:
+		return hex.EncodeToString(raw), nil
+	case Base32:
+		return string(base32Prefix) + crockford.EncodeToString(raw), nil
+	case Base58:
+		if len(raw) > maxBase58Bytes {
+			return "", fmt.Errorf("%w: key too long for base58", ErrInvalidKey)
+		}
+		return string(base58Prefix) + base58Encode(raw), nil
+	case Base64URL:
+		return string(base64URLPrefix) + base64.RawURLEncoding.EncodeToString(raw), nil
+	default:
+		return "", ErrUnknownEncoding
+	}
+}
+
+// DetectEncoding reports the encoding of a key from its prefix. Hex keys have none
+// but always start with 0, since their version is below 4096.
+func DetectEncoding(encoded string) (Encoding, error) {
+	if encoded == "" {
+		return 0, errors.New("key: empty key")
+	}
+	switch encoded[0] {
+	case base32Prefix, base32Prefix - 'a' + 'A':
+		return Base32, nil
+	case base58Prefix:
+		return Base58, nil
+	case base64URLPrefix:
+		return Base64URL, nil
+	}
+	if isHexDigit(encoded[0]) {
+		return Hex, nil
+	}
+	return 0, ErrUnknownEncoding
+}
+
+// decodeText detects the encoding of a key and returns its binary form.
+func decodeText(encoded string) ([]byte, error) {
+	enc, err := DetectEncoding(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw []byte
+	switch enc {
+	case Hex:
+		raw, err = hex.DecodeString(encoded)
+	case Base32:
+		raw, err = decodeCrockford(encoded[1:])
+	case Base58:
+		raw, err = base58Decode(encoded[1:])
+	case Base64URL:
+		raw, err = base64.RawURLEncoding.Strict().DecodeString(encoded[1:])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to %s decode: %v", ErrInvalidKey, enc, err)
+	}
+	return raw, nil
+}
+
+// decodeCrockford rejects encodings with non-zero unused trailing bits, which
+// base32 has no strict mode for, so every key has a single base32 form.
+func decodeCrockford(s string) ([]byte, error) {
+	s = crockfordAliases.Replace(strings.ToUpper(s))
+	raw, err := crockford.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if crockford.EncodeToString(raw) != s {
+		return nil, errors.New("non-canonical encoding")
+	}
+	return raw, nil
+}
+
+func isHexDigit(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+// Base58 conversion takes quadratic time, so the length of keys is capped to keep
+// decoding cheap for untrusted input. 512 bytes take at most 700 characters.
+const (
+	maxBase58Bytes = 512
+	maxBase58Len   = 700
+)
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var base58Index = func() (index [256]int8) {
+	for i := range index {
+		index[i] = -1
+	}
+	for i := 0; i < len(base58Alphabet); i++ {
+		index[base58Alphabet[i]] = int8(i)
+	}
+	return index
+}()
+
+// base58Encode converts raw to base 58 as a big endian number, writing each
+// leading zero byte as a '1'. Keys are short, so the quadratic conversion is fine.
+func base58Encode(raw []byte) string {
+	zeros := 0
+	for zeros < len(raw) && raw[zeros] == 0 {
+		zeros++
+	}
+
+	// log(256) / log(58) < 1.37
+	digits := make([]byte, 0, len(raw)*137/100+1)
+	for _, b := range raw[zeros:] {
+		carry := int(b)
+		for i := range digits {
+			carry += int(digits[i]) << 8
+			digits[i] = byte(carry % 58)
+			carry /= 58
+		}
+		for carry > 0 {
+			digits = append(digits, byte(carry%58))
+			carry /= 58
+		}
+	}
+
+	out := make([]byte, zeros+len(digits))
+	for i := 0; i < zeros; i++ {
+		out[i] = '1'
+	}
+	for i, d := range digits {
+		out[len(out)-1-i] = base58Alphabet[d]
+	}
+	return string(out)
+}
+
+func base58Decode(s string) ([]byte, error) {
+	if len(s) > maxBase58Len {
+		return nil, errors.New("too long")
+	}
+
+	zeros := 0
+	for zeros < len(s) && s[zeros] == '1' {
+		zeros++
+	}
+
+	// Little endian base 256 digits of the number.
+	num := make([]byte, 0, len(s)*733/1000+1)
+	for i := zeros; i < len(s); i++ {
+		carry := int(base58Index[s[i]])
+		if carry < 0 {
+			return nil, fmt.Errorf("illegal base58 character %q at %d", s[i], i)
+		}
+		for j := range num {
+			carry += int(num[j]) * 58
+			num[j] = byte(carry)
+			carry >>= 8
+		}
+		for carry > 0 {
+			num = append(num, byte(carry))
+			carry >>= 8
+		}
+	}
+
+	out := make([]byte, zeros+len(num))
+	for i, b := range num {
+		out[len(out)-1-i] = b
+	}
+	return out, nil
+}
@@ -0,0 +1,198 @@
+package key
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+var encodings = []Encoding{Hex, Base32, Base58, Base64URL}
+
+// sampleKeys returns a key of every version. Registering a new version without
+// adding it here fails TestEncodingRoundTrip.
+func sampleKeys() map[Version]VersionedKey {
+	return map[Version]VersionedKey{
+		V1: &KeyV1{
+			Identifier:    []byte("identifier"),
+			IndexFileHash: 0x0123456789abcdef,
+		},
+		V2: &KeyV2{
+			Identifier:    []byte{0, 1, 2, 0xff},
+			IndexFileHash: 42,
+			CreatedAt:     time.Date(2025, 1, 2, 3, 4, 5, 6e6, time.UTC),
+			Flags:         Flags(0).WithTier(2),
+		},
+	}
+}
+
+func TestEncodingRoundTrip(t *testing.T) {
+	kf := testFactory()
+	samples := sampleKeys()
+
+	registryMu.RLock()
+	var versions []Version
+	for v := range codecRegistry {
+		versions = append(versions, v)
+	}
+	registryMu.RUnlock()
+
+	for _, v := range versions {
+		sample, ok := samples[v]
+		if !ok {
+			t.Fatalf("no sample key for version %d", v)
+		}
+		for _, enc := range encodings {
+			encoded, err := kf.EncodeKeyAs(sample, enc)
+			if err != nil {
+				t.Fatalf("%d/%s: %v", v, enc, err)
+			}
+			if got, err := DetectEncoding(encoded); err != nil || got != enc {
+				t.Fatalf("%d/%s: detected %s, %v", v, enc, got, err)
+			}
+
+			var decoded VersionedKey
+			switch sample.(type) {
+			case *KeyV1:
+				decoded, err = Parse[*KeyV1](encoded)
+			case *KeyV2:
+				decoded, err = Parse[*KeyV2](encoded)
+			}
+			if err != nil {
+				t.Fatalf("%d/%s: %v", v, enc, err)
+			}
+			if !reflect.DeepEqual(decoded, sample) {
+				t.Fatalf("%d/%s: decoded %+v, expected %+v", v, enc, decoded, sample)
+			}
+		}
+	}
+}
+
+func TestEncodingLengths(t *testing.T) {
+	kf := testFactory()
+	k := sampleKeys()[V1]
+
+	lengths := make(map[Encoding]int)
+	for _, enc := range encodings {
+		s, err := kf.EncodeKeyAs(k, enc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lengths[enc] = len(s)
+	}
+	if !(lengths[Base64URL] < lengths[Base32] && lengths[Base58] < lengths[Base32] && lengths[Base32] < lengths[Hex]) {
+		t.Fatalf("unexpected lengths %v", lengths)
+	}
+}
+
+func TestFactoryEncoding(t *testing.T) {
+	kf := NewKeyFactory(KeyFactoryParams{Encoding: Base64URL})
+	s, err := kf.EncodeKey(sampleKeys()[V1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s[0] != 'u' {
+		t.Fatalf("factory encoding ignored: %s", s)
+	}
+
+	// Decode only accepts hex, as before.
+	if _, err := Decode[*KeyV1](s); err == nil {
+		t.Fatal("Decode accepted base64url")
+	}
+	if _, err := kf.EncodeKeyAs(sampleKeys()[V1], Encoding(99)); err != ErrUnknownEncoding {
+		t.Fatalf("expected ErrUnknownEncoding, got %v", err)
+	}
+}
+
+func TestBase58(t *testing.T) {
+	for _, c := range []struct {
+		raw  []byte
+		text string
+	}{
+		{nil, ""},
+		{[]byte{0}, "1"},
+		{[]byte{0, 0, 0x28, 0x7f, 0xb4, 0xcd}, "11233QC4"},
+		{[]byte("Hello World!"), "2NEpo7TZRRrLZSi2U"},
+		{[]byte{0xff}, "5Q"},
+	} {
+		if got := base58Encode(c.raw); got != c.text {
+			t.Fatalf("base58Encode(%x) = %q, expected %q", c.raw, got, c.text)
+		}
+		got, err := base58Decode(c.text)
+		if err != nil || !bytes.Equal(got, c.raw) {
+			t.Fatalf("base58Decode(%q) = %x, %v", c.text, got, err)
+		}
+	}
+
+	if _, err := base58Decode("0OIl"); err == nil {
+		t.Fatal("decoded characters outside the alphabet")
+	}
+}
+
+func TestCrockford(t *testing.T) {
+	kf := testFactory()
+	encoded, err := kf.EncodeKeyAs(sampleKeys()[V1], Base32)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Lowercase and the I, L and O look-alikes decode to the same key.
+	sloppy := strings.ToLower(strings.ReplaceAll(strings.ReplaceAll(encoded, "0", "O"), "1", "l"))
+	if sloppy == strings.ToLower(encoded) {
+		t.Fatal("test key has no 0 or 1 to replace")
+	}
+	a, err := Parse[*KeyV1](encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Parse[*KeyV1](sloppy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Fatal("look-alike characters decoded differently")
+	}
+
+	// The 38 byte key leaves the lowest bit of the last character unused; setting it is rejected.
+	const alphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	last := strings.IndexByte(alphabet, encoded[len(encoded)-1])
+	noncanonical := encoded[:len(encoded)-1] + string(alphabet[last^1])
+	if _, err := Parse[*KeyV1](noncanonical); err == nil {
+		t.Fatal("accepted a non-canonical encoding")
+	}
+}
+
+// Like hex, base32 keeps V2 keys in creation order.
+func TestBase32SortsByTime(t *testing.T) {
+	kf := testFactory()
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	var encoded []string
+	for i := 0; i < 100; i++ {
+		k := &KeyV2{
+			Identifier: []byte{byte(255 - i)},
+			CreatedAt:  base.Add(time.Duration(i*i) * time.Millisecond),
+		}
+		s, err := kf.EncodeKeyAs(k, Base32)
+		if err != nil {
+			t.Fatal(err)
+		}
+		encoded = append(encoded, s)
+	}
+	if !sort.StringsAreSorted(encoded) {
+		t.Fatal("base32 keys do not sort by creation time")
+	}
+}
+
+func TestDetectEncoding(t *testing.T) {
+	for _, s := range []string{"", "x123", "-abc"} {
+		if _, err := DetectEncoding(s); err == nil {
+			t.Fatalf("detected an encoding for %q", s)
+		}
+	}
+	if _, err := Parse[*KeyV1]("u!!!"); err == nil {
+		t.Fatal("decoded invalid base64url")
+	}
+}
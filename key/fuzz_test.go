@@ -0,0 +1,121 @@
+package key
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func registeredCodecs() map[Version]Codec {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	codecs := make(map[Version]Codec, len(codecRegistry))
+	for v, c := range codecRegistry {
+		codecs[v] = c
+	}
+	return codecs
+}
+
+func fuzzSeeds(t testing.TB) [][]byte {
+	var seeds [][]byte
+	for _, k := range sampleKeys() {
+		raw, err := k.EncodeBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		seeds = append(seeds, raw, raw[:len(raw)-1], append(bytes.Clone(raw), 0))
+	}
+	return append(seeds, nil, []byte{0}, []byte{0, 0}, []byte{0, 1, 0xff, 0xff})
+}
+
+// FuzzDecodeBinary feeds arbitrary bytes to every registered codec. Decoding must
+// never panic, and whatever a codec accepts must be the exact encoding of the key
+// it returns, so no trailing or ignored bytes get through.
+func FuzzDecodeBinary(f *testing.F) {
+	for _, seed := range fuzzSeeds(f) {
+		f.Add(seed)
+	}
+
+	codecs := registeredCodecs()
+	f.Fuzz(func(t *testing.T, payload []byte) {
+		for v, codec := range codecs {
+			k, err := codec.Decode(payload)
+			if err != nil {
+				continue
+			}
+			if k.Version() != v {
+				t.Fatalf("codec %d returned a version %d key", v, k.Version())
+			}
+
+			raw, err := codec.Encode(k)
+			if err != nil {
+				t.Fatalf("codec %d cannot encode the key it decoded: %v", v, err)
+			}
+			var version [2]byte
+			binary.BigEndian.PutUint16(version[:], v.Output())
+			if !bytes.Equal(raw, append(version[:], payload...)) {
+				t.Fatalf("codec %d accepted %x but encodes it as %x", v, payload, raw)
+			}
+		}
+	})
+}
+
+// FuzzParse checks the text decoding path, including the encoding detection.
+func FuzzParse(f *testing.F) {
+	kf := testFactory()
+	for _, k := range sampleKeys() {
+		for _, enc := range encodings {
+			s, err := kf.EncodeKeyAs(k, enc)
+			if err != nil {
+				f.Fatal(err)
+			}
+			f.Add(s)
+			f.Add(s[:len(s)-1])
+			f.Add(s + "0")
+		}
+	}
+	f.Add("")
+	f.Add("c")
+	f.Add("z1111")
+	f.Add("u-_")
+
+	f.Fuzz(func(t *testing.T, s string) {
+		Decode[*KeyV1](s)
+		Decode[*KeyV2](s)
+
+		var parsed []VersionedKey
+		if k, err := Parse[*KeyV1](s); err == nil {
+			parsed = append(parsed, k)
+		}
+		if k, err := Parse[*KeyV2](s); err == nil {
+			parsed = append(parsed, k)
+		}
+
+		if k, v, err := DecodeAny(s); err == nil {
+			if k.Version() != v {
+				t.Fatalf("DecodeAny returned version %d for a version %d key", v, k.Version())
+			}
+			if peeked, err := PeekVersion(s); err != nil || peeked != v {
+				t.Fatalf("PeekVersion = %d, %v but DecodeAny found version %d", peeked, err, v)
+			}
+		}
+
+		enc, err := DetectEncoding(s)
+		if err != nil && len(parsed) > 0 {
+			t.Fatalf("parsed %q without an encoding", s)
+		}
+		for _, k := range parsed {
+			raw, err := k.EncodeBinary()
+			if err != nil {
+				t.Fatalf("cannot encode a parsed key: %v", err)
+			}
+			again, err := enc.Encode(raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := decodeText(again); err != nil {
+				t.Fatalf("re-encoded key %q does not decode: %v", again, err)
+			}
+		}
+	})
+}
@@ -1,18 +1,22 @@
 package key
 
 type KeyFactory struct {
-	nodeID uint64
-	diskId uint64
+	nodeID   uint64
+	diskId   uint64
+	encoding Encoding
 }
 
 type KeyFactoryParams struct {
 	NodeId uint64
 	DiskID uint64
+	// Encoding of the keys returned by EncodeKey, Hex if unset.
+	Encoding Encoding
 }
 
 func NewKeyFactory(params KeyFactoryParams) *KeyFactory {
 	return &KeyFactory{
-		nodeID: params.NodeId,
-		diskId: params.DiskID,
+		nodeID:   params.NodeId,
+		diskId:   params.DiskID,
+		encoding: params.Encoding,
 	}
 }
@@ -1,6 +1,7 @@
 package key
 
 import (
+	"errors"
 	"fmt"
 	"hash/fnv"
 	"testing"
@@ -16,12 +17,11 @@ func TestKey(t *testing.T) {
 
 	data, err := kf.EncodeKey(&KeyV1{
 		IndexFileHash: hashx.FNV64.HashString("test_file"),
-		Identifier: GenerateIdentifier(16),
+		Identifier:    GenerateIdentifier(16),
 	})
 	if err != nil {
 		panic(err)
 	}
-	
 
 	fmt.Println(data)
 
@@ -33,9 +33,32 @@ func TestKey(t *testing.T) {
 	fmt.Println(k)
 }
 
-
 func hash(data string) uint64 {
 	h := fnv.New64a()
 	h.Write([]byte(data))
 	return h.Sum64()
-}
\ No newline at end of file
+}
+
+func TestDecodeV1Malformed(t *testing.T) {
+	kf := NewKeyFactory(KeyFactoryParams{NodeId: 1, DiskID: 2})
+	valid, err := kf.EncodeKey(&KeyV1{Identifier: []byte("id")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, encoded := range map[string]string{
+		// Long enough for the old length check but shorter than the fixed fields.
+		"short":    valid[:2*20],
+		"no id":    valid[:len(valid)-2],
+		"huge id":  "0000ffff" + valid[8:],
+		"trailing": valid + "00",
+	} {
+		if _, err := Decode[*KeyV1](encoded); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("%s: expected ErrInvalidKey, got %v", name, err)
+		}
+	}
+
+	if _, err := kf.EncodeKey(&KeyV1{Identifier: make([]byte, 1<<16)}); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("expected ErrInvalidKey, got %v", err)
+	}
+}
@@ -3,8 +3,15 @@ package key
 import (
 	"encoding/binary"
 	"errors"
+	"fmt"
+	"math"
 )
 
+var ErrInvalidKey = errors.New("key: invalid key")
+
+// v1HeaderLen is the size of a V1 key without its identifier.
+const v1HeaderLen = 2 + 2 + 3*8
+
 func init() {
 	RegisterDecoder(V1, Codec{
 		Encode: func(k VersionedKey) ([]byte, error) {
@@ -15,9 +22,9 @@ func init() {
 }
 
 type KeyV1 struct {
-	NodeID uint64
-	DiskID uint64
-	Identifier []byte
+	NodeID        uint64
+	DiskID        uint64
+	Identifier    []byte
 	IndexFileHash uint64
 }
 
@@ -26,46 +33,61 @@ func (k *KeyV1) Version() Version {
 }
 
 func (k *KeyV1) EncodeBinary() ([]byte, error) {
-	identifierLen := uint16(len(k.Identifier))
+	if len(k.Identifier) > math.MaxUint16 {
+		return nil, fmt.Errorf("%w: identifier longer than 65535 bytes", ErrInvalidKey)
+	}
+	identifierLen := len(k.Identifier)
 
-	bufLen := 4+3*8+identifierLen
-	buf := make([]byte, bufLen)
+	buf := make([]byte, v1HeaderLen+identifierLen)
 
-	binary.BigEndian.PutUint16(buf[0:2],   k.Version().Output())
-	binary.BigEndian.PutUint16(buf[2:4],   identifierLen)
-	binary.BigEndian.PutUint64(buf[4:12],  k.IndexFileHash)
+	binary.BigEndian.PutUint16(buf[0:2], k.Version().Output())
+	binary.BigEndian.PutUint16(buf[2:4], uint16(identifierLen))
+	binary.BigEndian.PutUint64(buf[4:12], k.IndexFileHash)
 	binary.BigEndian.PutUint64(buf[12:20], k.NodeID)
 	binary.BigEndian.PutUint64(buf[20:28], k.DiskID)
 
-
-	copy(buf[28:28+identifierLen], k.Identifier[0:identifierLen])
+	copy(buf[v1HeaderLen:], k.Identifier)
 
 	return buf, nil
 }
 
+func (k *KeyV1) GetNodeID() uint64 {
+	return k.NodeID
+}
+
+func (k *KeyV1) GetDiskID() uint64 {
+	return k.DiskID
+}
+
 func (k *KeyV1) SetIDs(nodeID, diskID uint64) {
 	k.NodeID = nodeID
 	k.DiskID = diskID
 }
 
+// decodeV1 decodes a V1 payload, which excludes the two version bytes.
+// The payload must be exactly as long as its identifier length says.
 func decodeV1(buf []byte) (VersionedKey, error) {
-	if len(buf) < 2+8+8 {
-		return nil, errors.New("key: invalid V1 key: too short")
+	const payloadHeaderLen = v1HeaderLen - 2
+	if len(buf) < payloadHeaderLen {
+		return nil, fmt.Errorf("%w: V1 key too short", ErrInvalidKey)
 	}
 
-	identifierLen := binary.BigEndian.Uint16(buf[0:2])
+	identifierLen := int(binary.BigEndian.Uint16(buf[0:2]))
+	if len(buf) != payloadHeaderLen+identifierLen {
+		return nil, fmt.Errorf("%w: V1 key is %d bytes but its identifier needs %d", ErrInvalidKey, len(buf)+2, v1HeaderLen+identifierLen)
+	}
 
 	indexFileHash := binary.BigEndian.Uint64(buf[2:10])
 	nodeId := binary.BigEndian.Uint64(buf[10:18])
 	diskId := binary.BigEndian.Uint64(buf[18:26])
-	
+
 	idBuf := make([]byte, identifierLen)
-	copy(idBuf[0:identifierLen], buf[26:26+identifierLen])
+	copy(idBuf, buf[payloadHeaderLen:])
 
 	return &KeyV1{
-		NodeID: nodeId,
-		DiskID: diskId,
-		Identifier: idBuf,
+		NodeID:        nodeId,
+		DiskID:        diskId,
+		Identifier:    idBuf,
 		IndexFileHash: indexFileHash,
 	}, nil
-}
\ No newline at end of file
+}
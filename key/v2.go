@@ -0,0 +1,203 @@
+package key
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/atlastore/belt/hashx"
+)
+
+var ErrChecksum = errors.New("key: checksum mismatch")
+
+func init() {
+	RegisterDecoder(V2, Codec{
+		Encode: func(k VersionedKey) ([]byte, error) {
+			return k.EncodeBinary()
+		},
+		Decode: decodeV2,
+	})
+}
+
+// Flags carries per-key storage hints. The low nibble is the storage tier and the
+// next one the replication class; the high byte is free for applications.
+type Flags uint16
+
+const (
+	tierMask        Flags = 0x000f
+	replicationMask Flags = 0x00f0
+)
+
+// Tier returns the storage tier, 0 to 15.
+func (f Flags) Tier() uint8 {
+	return uint8(f & tierMask)
+}
+
+// WithTier returns f with the storage tier set to the low 4 bits of tier.
+func (f Flags) WithTier(tier uint8) Flags {
+	return f&^tierMask | Flags(tier)&tierMask
+}
+
+// Replication returns the replication class, 0 to 15.
+func (f Flags) Replication() uint8 {
+	return uint8(f & replicationMask >> 4)
+}
+
+// WithReplication returns f with the replication class set to the low 4 bits of class.
+func (f Flags) WithReplication(class uint8) Flags {
+	return f&^replicationMask | Flags(class)<<4&replicationMask
+}
+
+// KeyV2 adds a creation time, flags and a CRC32C checksum to the fields of KeyV1.
+//
+// The creation time, in milliseconds, directly follows the version, so encoded V2
+// keys sort by creation time, as does Compare. The checksum covers the whole key,
+// so Decode rejects corrupted or mistyped keys.
+type KeyV2 struct {
+	NodeID        uint64
+	DiskID        uint64
+	Identifier    []byte
+	IndexFileHash uint64
+	// CreatedAt is stored with millisecond precision and must be between
+	// the Unix epoch and the year 10889.
+	CreatedAt time.Time
+	Flags     Flags
+}
+
+const (
+	v2HeaderLen   = 2 + 6 + 2 + 3*8 + 2
+	v2ChecksumLen = 4
+	maxMillis48   = 1<<48 - 1
+)
+
+// NewKeyV2 returns a key with the factory's IDs, created now.
+func (kf *KeyFactory) NewKeyV2(identifier []byte, indexFileHash uint64, flags Flags) *KeyV2 {
+	return &KeyV2{
+		NodeID:        kf.nodeID,
+		DiskID:        kf.diskId,
+		Identifier:    identifier,
+		IndexFileHash: indexFileHash,
+		CreatedAt:     time.UnixMilli(time.Now().UnixMilli()).UTC(),
+		Flags:         flags,
+	}
+}
+
+// Version returns V2.
+func (k *KeyV2) Version() Version {
+	return V2
+}
+
+// EncodeBinary encodes the key followed by its CRC32C checksum. It fails if the
+// identifier is longer than 65535 bytes or the creation time does not fit in 48
+// bits of milliseconds since the Unix epoch.
+func (k *KeyV2) EncodeBinary() ([]byte, error) {
+	if len(k.Identifier) > math.MaxUint16 {
+		return nil, fmt.Errorf("%w: identifier longer than 65535 bytes", ErrInvalidKey)
+	}
+	millis := k.CreatedAt.UnixMilli()
+	if k.CreatedAt.IsZero() || millis < 0 || millis > maxMillis48 {
+		return nil, fmt.Errorf("%w: creation time out of range", ErrInvalidKey)
+	}
+
+	buf := make([]byte, v2HeaderLen, v2HeaderLen+len(k.Identifier)+v2ChecksumLen)
+	binary.BigEndian.PutUint16(buf[0:2], k.Version().Output())
+	putUint48(buf[2:8], uint64(millis))
+	binary.BigEndian.PutUint16(buf[8:10], uint16(k.Flags))
+	binary.BigEndian.PutUint64(buf[10:18], k.NodeID)
+	binary.BigEndian.PutUint64(buf[18:26], k.DiskID)
+	binary.BigEndian.PutUint64(buf[26:34], k.IndexFileHash)
+	binary.BigEndian.PutUint16(buf[34:36], uint16(len(k.Identifier)))
+	buf = append(buf, k.Identifier...)
+
+	return binary.BigEndian.AppendUint32(buf, hashx.CRC32C.HashBytes(buf)), nil
+}
+
+// GetNodeID returns the ID of the node that stores the key.
+func (k *KeyV2) GetNodeID() uint64 {
+	return k.NodeID
+}
+
+// GetDiskID returns the ID of the disk that stores the key.
+func (k *KeyV2) GetDiskID() uint64 {
+	return k.DiskID
+}
+
+// SetIDs moves the key to another node and disk. The checksum is computed on
+// encoding, so it follows.
+func (k *KeyV2) SetIDs(nodeID, diskID uint64) {
+	k.NodeID = nodeID
+	k.DiskID = diskID
+}
+
+// Compare orders keys the same way their encodings sort: by creation time, then
+// by the remaining fields.
+func (k *KeyV2) Compare(other *KeyV2) int {
+	if c := cmp.Compare(k.CreatedAt.UnixMilli(), other.CreatedAt.UnixMilli()); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(k.Flags, other.Flags); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(k.NodeID, other.NodeID); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(k.DiskID, other.DiskID); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(k.IndexFileHash, other.IndexFileHash); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(len(k.Identifier), len(other.Identifier)); c != 0 {
+		return c
+	}
+	return bytes.Compare(k.Identifier, other.Identifier)
+}
+
+// decodeV2 decodes a V2 payload, which excludes the two version bytes.
+func decodeV2(buf []byte) (VersionedKey, error) {
+	const payloadHeaderLen = v2HeaderLen - 2
+	if len(buf) < payloadHeaderLen+v2ChecksumLen {
+		return nil, fmt.Errorf("%w: V2 key too short", ErrInvalidKey)
+	}
+
+	identifierLen := int(binary.BigEndian.Uint16(buf[32:34]))
+	if len(buf) != payloadHeaderLen+identifierLen+v2ChecksumLen {
+		return nil, fmt.Errorf("%w: V2 key is %d bytes but its identifier needs %d", ErrInvalidKey, len(buf)+2, v2HeaderLen+identifierLen+v2ChecksumLen)
+	}
+
+	body := buf[:len(buf)-v2ChecksumLen]
+	var version [2]byte
+	binary.BigEndian.PutUint16(version[:], V2.Output())
+	crc := hashx.CRC32C.HashBytes(append(version[:], body...))
+	if crc != binary.BigEndian.Uint32(buf[len(body):]) {
+		return nil, ErrChecksum
+	}
+
+	idBuf := make([]byte, identifierLen)
+	copy(idBuf, body[payloadHeaderLen:])
+
+	return &KeyV2{
+		NodeID:        binary.BigEndian.Uint64(buf[8:16]),
+		DiskID:        binary.BigEndian.Uint64(buf[16:24]),
+		Identifier:    idBuf,
+		IndexFileHash: binary.BigEndian.Uint64(buf[24:32]),
+		CreatedAt:     time.UnixMilli(int64(uint48(buf[0:6]))).UTC(),
+		Flags:         Flags(binary.BigEndian.Uint16(buf[6:8])),
+	}, nil
+}
+
+func putUint48(b []byte, v uint64) {
+	_ = b[5]
+	b[0] = byte(v >> 40)
+	b[1] = byte(v >> 32)
+	binary.BigEndian.PutUint32(b[2:6], uint32(v))
+}
+
+func uint48(b []byte) uint64 {
+	_ = b[5]
+	return uint64(b[0])<<40 | uint64(b[1])<<32 | uint64(binary.BigEndian.Uint32(b[2:6]))
+}
@@ -0,0 +1,141 @@
+package key
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/atlastore/belt/hashx"
+)
+
+func testFactory() *KeyFactory {
+	return NewKeyFactory(KeyFactoryParams{
+		NodeId: hash("node 1"),
+		DiskID: hash("disk 1"),
+	})
+}
+
+func TestKeyV2(t *testing.T) {
+	kf := testFactory()
+	flags := Flags(0).WithTier(3).WithReplication(2)
+	k := kf.NewKeyV2(GenerateIdentifier(16), hashx.FNV64.HashString("test_file"), flags)
+
+	encoded, err := kf.EncodeKey(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Decode[*KeyV2](encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.NodeID != k.NodeID || got.DiskID != k.DiskID || got.IndexFileHash != k.IndexFileHash ||
+		!bytes.Equal(got.Identifier, k.Identifier) || !got.CreatedAt.Equal(k.CreatedAt) || got.Flags != k.Flags {
+		t.Fatalf("decoded %+v, expected %+v", got, k)
+	}
+	if got.Flags.Tier() != 3 || got.Flags.Replication() != 2 {
+		t.Fatalf("flags %#x", got.Flags)
+	}
+	if time.Since(got.CreatedAt) > time.Minute {
+		t.Fatalf("created at %v", got.CreatedAt)
+	}
+}
+
+func TestKeyV2Checksum(t *testing.T) {
+	kf := testFactory()
+	encoded, err := kf.EncodeKey(kf.NewKeyV2([]byte("object"), 1, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := hex.DecodeString(encoded)
+
+	// Every single bit flip after the version must be caught.
+	for i := 2; i < len(raw); i++ {
+		for bit := 0; bit < 8; bit++ {
+			corrupt := bytes.Clone(raw)
+			corrupt[i] ^= 1 << bit
+			if _, err := Decode[*KeyV2](hex.EncodeToString(corrupt)); err == nil {
+				t.Fatalf("flipping bit %d of byte %d went unnoticed", bit, i)
+			}
+		}
+	}
+
+	raw[len(raw)-1] ^= 1
+	if _, err := Decode[*KeyV2](hex.EncodeToString(raw)); !errors.Is(err, ErrChecksum) {
+		t.Fatalf("expected ErrChecksum, got %v", err)
+	}
+}
+
+func TestKeyV2SortsByTime(t *testing.T) {
+	kf := testFactory()
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	var keys []*KeyV2
+	var encoded []string
+	for i := 0; i < 50; i++ {
+		// Later keys get smaller IDs and hashes, so only the time can order them.
+		k := &KeyV2{
+			Identifier:    []byte{byte(255 - i)},
+			IndexFileHash: uint64(1000 - i),
+			CreatedAt:     base.Add(time.Duration(i) * 1500 * time.Microsecond),
+			Flags:         Flags(50 - i),
+		}
+		s, err := kf.EncodeKey(k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, k)
+		encoded = append(encoded, s)
+	}
+
+	if !sort.StringsAreSorted(encoded) {
+		t.Fatal("encoded keys do not sort by creation time")
+	}
+	for i := 1; i < len(keys); i++ {
+		if keys[i-1].Compare(keys[i]) >= 0 {
+			t.Fatalf("Compare does not order key %d before %d", i-1, i)
+		}
+		a, _ := keys[i-1].EncodeBinary()
+		b, _ := keys[i].EncodeBinary()
+		if bytes.Compare(a, b) != keys[i-1].Compare(keys[i]) {
+			t.Fatal("Compare disagrees with the encoding")
+		}
+	}
+}
+
+func TestKeyV2Errors(t *testing.T) {
+	kf := testFactory()
+	if _, err := kf.EncodeKey(&KeyV2{}); err == nil {
+		t.Fatal("a key without a creation time must not encode")
+	}
+	if _, err := kf.EncodeKey(&KeyV2{CreatedAt: time.Unix(-1, 0)}); err == nil {
+		t.Fatal("a key created before 1970 must not encode")
+	}
+	if _, err := kf.EncodeKey(&KeyV2{CreatedAt: time.Now(), Identifier: make([]byte, 1<<16)}); err == nil {
+		t.Fatal("an oversized identifier must not encode")
+	}
+
+	encoded, _ := kf.EncodeKey(kf.NewKeyV2(nil, 0, 0))
+	for _, s := range []string{encoded[:len(encoded)-2], encoded + "00", encoded[:20]} {
+		if _, err := Decode[*KeyV2](s); err == nil {
+			t.Fatalf("decoded malformed key %s", s)
+		}
+	}
+	if _, err := Decode[*KeyV1](encoded); err == nil {
+		t.Fatal("decoded a V2 key as V1")
+	}
+}
+
+func TestFlags(t *testing.T) {
+	f := Flags(0xff00).WithTier(0x1f).WithReplication(7)
+	if f.Tier() != 0xf || f.Replication() != 7 || f&0xff00 != 0xff00 {
+		t.Fatalf("flags %#x", f)
+	}
+	f = f.WithTier(1)
+	if f.Tier() != 1 || f.Replication() != 7 {
+		t.Fatalf("flags %#x", f)
+	}
+}
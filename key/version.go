@@ -5,6 +5,7 @@ type Version uint16
 
 const (
 	V1 Version = iota
+	V2
 )
 
 const CurrVersion = V1
@@ -17,4 +18,14 @@ type VersionedKey interface {
 	EncodeBinary() ([]byte, error)
 	Version() Version
 	SetIDs(nodeID, diskID uint64)
-}
\ No newline at end of file
+}
+
+// Locator is implemented by every key version of this package, so code that
+// routes keys can find their node and disk without a type switch. Keys of other
+// packages may implement it too; the getters are named like protobuf getters
+// because the key structs already have NodeID and DiskID fields.
+type Locator interface {
+	VersionedKey
+	GetNodeID() uint64
+	GetDiskID() uint64
+}